@@ -0,0 +1,207 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strconv"
+)
+
+// Category is a canonical category of an error, modeled on gRPC status codes. Values are
+// equal to the codes of google.golang.org/grpc/codes, so codes.Code(category) is a valid
+// conversion.
+//
+// Categories are given to errors and sentinels as specs:
+//
+//	var ErrUserNotFound = errors.NewSentinel("user not found", errors.WithSpec(errors.CategoryNotFound))
+//
+//	return errors.Spec(errors.Wrap(err, "parse request"), errors.CategoryInvalidArgument)
+type Category uint32
+
+const (
+	// CategoryOK is not an error, it is the category of nil errors.
+	CategoryOK Category = iota
+	// CategoryCanceled means the operation was canceled, typically by the caller.
+	CategoryCanceled
+	// CategoryUnknown is for errors without a category.
+	CategoryUnknown
+	// CategoryInvalidArgument means the caller gave an invalid argument, regardless of the system state.
+	CategoryInvalidArgument
+	// CategoryDeadlineExceeded means the operation expired before completion.
+	CategoryDeadlineExceeded
+	// CategoryNotFound means some requested entity was not found.
+	CategoryNotFound
+	// CategoryAlreadyExists means an entity the caller attempted to create already exists.
+	CategoryAlreadyExists
+	// CategoryPermissionDenied means the caller has no permission to execute the operation.
+	CategoryPermissionDenied
+	// CategoryResourceExhausted means some resource has been exhausted, like a quota.
+	CategoryResourceExhausted
+	// CategoryFailedPrecondition means the system is not in a state required for the operation.
+	CategoryFailedPrecondition
+	// CategoryAborted means the operation was aborted, typically due to a concurrency conflict.
+	CategoryAborted
+	// CategoryOutOfRange means the operation was attempted past the valid range.
+	CategoryOutOfRange
+	// CategoryUnimplemented means the operation is not implemented or not supported.
+	CategoryUnimplemented
+	// CategoryInternal means some invariants expected by the system have been broken.
+	CategoryInternal
+	// CategoryUnavailable means the service is currently unavailable, the operation can be retried.
+	CategoryUnavailable
+	// CategoryDataLoss means unrecoverable data loss or corruption.
+	CategoryDataLoss
+	// CategoryUnauthenticated means the caller has no valid authentication credentials.
+	CategoryUnauthenticated
+)
+
+var categoryNames = [...]string{
+	CategoryOK:                 "OK",
+	CategoryCanceled:           "Canceled",
+	CategoryUnknown:            "Unknown",
+	CategoryInvalidArgument:    "InvalidArgument",
+	CategoryDeadlineExceeded:   "DeadlineExceeded",
+	CategoryNotFound:           "NotFound",
+	CategoryAlreadyExists:      "AlreadyExists",
+	CategoryPermissionDenied:   "PermissionDenied",
+	CategoryResourceExhausted:  "ResourceExhausted",
+	CategoryFailedPrecondition: "FailedPrecondition",
+	CategoryAborted:            "Aborted",
+	CategoryOutOfRange:         "OutOfRange",
+	CategoryUnimplemented:      "Unimplemented",
+	CategoryInternal:           "Internal",
+	CategoryUnavailable:        "Unavailable",
+	CategoryDataLoss:           "DataLoss",
+	CategoryUnauthenticated:    "Unauthenticated",
+}
+
+func (c Category) String() string {
+	if int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+
+	return "Category(" + strconv.FormatUint(uint64(c), 10) + ")"
+}
+
+var categoryHTTPStatuses = [...]int{
+	CategoryOK:                 200, // OK.
+	CategoryCanceled:           499, // Client Closed Request.
+	CategoryUnknown:            500, // Internal Server Error.
+	CategoryInvalidArgument:    400, // Bad Request.
+	CategoryDeadlineExceeded:   504, // Gateway Timeout.
+	CategoryNotFound:           404, // Not Found.
+	CategoryAlreadyExists:      409, // Conflict.
+	CategoryPermissionDenied:   403, // Forbidden.
+	CategoryResourceExhausted:  429, // Too Many Requests.
+	CategoryFailedPrecondition: 400, // Bad Request.
+	CategoryAborted:            409, // Conflict.
+	CategoryOutOfRange:         400, // Bad Request.
+	CategoryUnimplemented:      501, // Not Implemented.
+	CategoryInternal:           500, // Internal Server Error.
+	CategoryUnavailable:        503, // Service Unavailable.
+	CategoryDataLoss:           500, // Internal Server Error.
+	CategoryUnauthenticated:    401, // Unauthorized.
+}
+
+// HTTPStatus returns the HTTP status code of the category, following the mapping of
+// google.rpc.Code. Unknown categories map to 500.
+func (c Category) HTTPStatus() int {
+	if int(c) < len(categoryHTTPStatuses) {
+		return categoryHTTPStatuses[c]
+	}
+
+	return 500
+}
+
+// CategoryOfHTTPStatus returns the category of the HTTP status code, it is meant for clients
+// restoring categories of errors got from HTTP APIs. Statuses shared by several categories
+// map to the most general of them: 400 is [CategoryInvalidArgument] and 409 is [CategoryAborted].
+func CategoryOfHTTPStatus(status int) Category {
+	switch status {
+	case 400: // Bad Request.
+		return CategoryInvalidArgument
+	case 401: // Unauthorized.
+		return CategoryUnauthenticated
+	case 403: // Forbidden.
+		return CategoryPermissionDenied
+	case 404: // Not Found.
+		return CategoryNotFound
+	case 409: // Conflict.
+		return CategoryAborted
+	case 412: // Precondition Failed.
+		return CategoryFailedPrecondition
+	case 416: // Range Not Satisfiable.
+		return CategoryOutOfRange
+	case 429: // Too Many Requests.
+		return CategoryResourceExhausted
+	case 499: // Client Closed Request.
+		return CategoryCanceled
+	case 501: // Not Implemented.
+		return CategoryUnimplemented
+	case 503: // Service Unavailable.
+		return CategoryUnavailable
+	case 504: // Gateway Timeout.
+		return CategoryDeadlineExceeded
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return CategoryOK
+	case status >= 500 && status < 600:
+		return CategoryInternal
+	default:
+		return CategoryUnknown
+	}
+}
+
+// CategoryOf returns the category of the error given with [Spec] or [WithSpec], the outermost
+// one wins. Errors without it get defaults for well known errors of the standard library:
+//
+//   - [context.Canceled] is [CategoryCanceled].
+//   - [context.DeadlineExceeded] and errors with Timeout() returning true are [CategoryDeadlineExceeded].
+//   - [fs.ErrNotExist] is [CategoryNotFound], [fs.ErrExist] is [CategoryAlreadyExists].
+//   - [fs.ErrPermission] is [CategoryPermissionDenied].
+//   - [fs.ErrInvalid], [strconv.ErrSyntax] are [CategoryInvalidArgument], [strconv.ErrRange] is [CategoryOutOfRange].
+//   - [errors.ErrUnsupported] is [CategoryUnimplemented].
+//
+// Other errors are [CategoryUnknown] and nil is [CategoryOK].
+func CategoryOf(err error) Category {
+	if err == nil {
+		return CategoryOK
+	}
+
+	if c, ok := AsSpec[Category](err); ok {
+		return c
+	}
+
+	switch {
+	case Is(err, context.Canceled):
+		return CategoryCanceled
+	case Is(err, context.DeadlineExceeded):
+		return CategoryDeadlineExceeded
+	case Is(err, fs.ErrNotExist):
+		return CategoryNotFound
+	case Is(err, fs.ErrExist):
+		return CategoryAlreadyExists
+	case Is(err, fs.ErrPermission):
+		return CategoryPermissionDenied
+	case Is(err, fs.ErrInvalid), Is(err, strconv.ErrSyntax):
+		return CategoryInvalidArgument
+	case Is(err, strconv.ErrRange):
+		return CategoryOutOfRange
+	case Is(err, errors.ErrUnsupported):
+		return CategoryUnimplemented
+	}
+
+	if t, ok := AsType[timeoutError](err); ok && t.Timeout() {
+		return CategoryDeadlineExceeded
+	}
+
+	return CategoryUnknown
+}
+
+// timeoutError is implemented by net.Error and errors of the os package.
+type timeoutError interface {
+	error
+	Timeout() bool
+}
@@ -0,0 +1,58 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/sirkon/errors"
+)
+
+func ExampleCategoryOf() {
+	errUserNotFound := errors.NewSentinel("user not found", errors.WithSpec(errors.CategoryNotFound))
+
+	for _, err := range []error{
+		nil,
+		errors.Wrap(errUserNotFound, "get user"),
+		errors.Spec(errors.New("bad request"), errors.CategoryInvalidArgument),
+		errors.Spec(errors.Wrap(errUserNotFound, "get user"), errors.CategoryInternal),
+		errors.Wrap(context.Canceled, "call storage"),
+		fmt.Errorf("read: %w", os.ErrDeadlineExceeded),
+		&fs.PathError{Op: "open", Path: "config.yaml", Err: fs.ErrNotExist},
+		errors.Just(fs.ErrPermission),
+		func() error { _, err := strconv.Atoi("x"); return err }(),
+		io.EOF,
+	} {
+		c := errors.CategoryOf(err)
+		fmt.Println(c, c.HTTPStatus(), uint32(c))
+	}
+
+	// Output:
+	// OK 200 0
+	// NotFound 404 5
+	// InvalidArgument 400 3
+	// Internal 500 13
+	// Canceled 499 1
+	// DeadlineExceeded 504 4
+	// NotFound 404 5
+	// PermissionDenied 403 7
+	// InvalidArgument 400 3
+	// Unknown 500 2
+}
+
+func ExampleCategoryOfHTTPStatus() {
+	for _, status := range []int{204, 404, 409, 418, 502, 503} {
+		fmt.Println(status, errors.CategoryOfHTTPStatus(status))
+	}
+
+	// Output:
+	// 204 OK
+	// 404 NotFound
+	// 409 Aborted
+	// 418 Unknown
+	// 502 Internal
+	// 503 Unavailable
+}
@@ -21,7 +21,7 @@ func (e *Error) Error() string {
 
 	for _, attr := range e.attrs {
 		switch attr.kind {
-		case errorAttrKindNew:
+		case errorAttrKindNew, errorAttrKindOutterNew:
 			links = append(links, attr.key)
 		case errorAttrKindWrap:
 			links = append(links, attr.key)
@@ -61,7 +61,7 @@ func (e *Error) Is(target error) bool {
 
 	for _, attr := range e.attrs {
 		switch attr.kind {
-		case errorAttrKindOutterWrap, errorAttrKindOutterJust:
+		case errorAttrKindOutterNew, errorAttrKindOutterWrap, errorAttrKindOutterJust, errorAttrKindPhantomJust:
 			if errors.Is(attr.value.Any().(error), target) {
 				return true
 			}
@@ -92,7 +92,7 @@ func (e *Error) As(target any) bool {
 
 	for _, attr := range e.attrs {
 		switch attr.kind {
-		case errorAttrKindOutterWrap, errorAttrKindOutterJust, errorAttrKindPhantomJust:
+		case errorAttrKindOutterNew, errorAttrKindOutterWrap, errorAttrKindOutterJust, errorAttrKindPhantomJust:
 			if errors.As(attr.value.Any().(error), target) {
 				return true
 			}
@@ -109,6 +109,8 @@ type errorAttrKind int8
 const (
 	errorAttrKindInvalid errorAttrKind = iota
 	errorAttrKindNew
+	// errorAttrKindOutterNew начинает ошибку с внешней, обычно sentinel, ошибки, ключ содержит её текст.
+	errorAttrKindOutterNew
 	errorAttrKindWrap
 	errorAttrKindOutterWrap
 	errorAttrKindJust
@@ -124,4 +126,6 @@ const (
 	// "ориентация" ошибки под конкретные задачи.
 	errorAttrKindMarker
 	errorAttrKindAny
+	// errorAttrKindRemote отмечает слой ошибки, полученный из другого процесса, ключ содержит имя сервиса.
+	errorAttrKindRemote
 )
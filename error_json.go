@@ -0,0 +1,468 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ContextMode selects the shape of an error context.
+type ContextMode int8
+
+const (
+	// ContextTree groups context by layers, like [SLogTreeContext] does.
+	ContextTree ContextMode = iota
+	// ContextFlat puts all context values on one level, like [SLogFlatContext] does.
+	ContextFlat
+)
+
+// AppendJSON appends error context as a JSON object straight from the error, without
+// building slog attributes. The result is the same [slog.JSONHandler] produces for a group
+// of [SLogTreeContext] or [SLogFlatContext] attributes, depending on the mode.
+//
+// Context values of basic kinds are encoded without allocations. Any values are encoded
+// with their json.Marshaler or encoding/json, errors without one with their texts. Non-finite
+// floats, which are not representable in JSON, are encoded as strings.
+func (e *Error) AppendJSON(dst []byte, mode ContextMode) []byte {
+	dst = append(dst, '{')
+	if mode == ContextFlat {
+		s := jsonFlatContextState{dst: dst}
+		s.feed(e.attrs)
+		s.appendSpecs()
+		dst = s.appendLocations(e.attrs)
+	} else {
+		s := jsonTreeContextState{dst: dst}
+		s.feed(e.attrs)
+		s.closeStage()
+		dst = s.dst
+	}
+
+	return append(dst, '}')
+}
+
+// JSONContext returns a [json.Marshaler] encoding error context with [Error.AppendJSON].
+// Put it into a [slog.JSONHandler] record to skip building of slog attributes:
+//
+//	logger.Error("failed", slog.Any("@err", errors.JSONContext(err, errors.ContextFlat)))
+func JSONContext(err *Error, mode ContextMode) json.Marshaler {
+	return jsonContext{err: err, mode: mode}
+}
+
+type jsonContext struct {
+	err  *Error
+	mode ContextMode
+}
+
+func (c jsonContext) MarshalJSON() ([]byte, error) {
+	return c.err.AppendJSON(make([]byte, 0, 256), c.mode), nil
+}
+
+type jsonTreeContextState struct {
+	dst []byte
+	// stageKey is the offset of the current stage key, stageBody of the first byte after its '{'.
+	stageKey  int
+	stageBody int
+	open      bool
+	empty     bool
+	specs     []string
+}
+
+func (s *jsonTreeContextState) feed(attrs []errorAttr) {
+	for _, attr := range attrs {
+		switch attr.kind {
+		case errorAttrKindMarker:
+			if showSpecs && s.open {
+				s.specs = append(s.specs, specValue(attr.mark()).String())
+			}
+			continue
+		case errorAttrKindNew:
+			s.openStage("NEW: ", attr.key)
+
+		case errorAttrKindOutterNew:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.openStage("NEW: ", attr.key)
+
+		case errorAttrKindWrap:
+			s.openStage("WRAP: ", attr.key)
+
+		case errorAttrKindOutterWrap:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.openStage("WRAP: ", attr.key)
+
+		case errorAttrKindJust:
+			s.openStage("CTX", "")
+
+		case errorAttrKindOutterJust:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.openStage("CTX", "")
+
+		case errorAttrKindPhantomJust:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+
+		case errorAttrKindLoc:
+			if s.open {
+				s.insertLocation(trimLocation(attr.value.String()))
+			}
+		case errorAttrKindRemote:
+			if s.open {
+				s.appendAttr("@remote", attr.value)
+			}
+		default:
+			if s.open {
+				s.appendAttr(attr.key, attr.value)
+			}
+		}
+
+		if showSpecs && s.open {
+			for _, mark := range layerSentinelSpecs(attr) {
+				s.specs = append(s.specs, specValue(mark).String())
+			}
+		}
+	}
+}
+
+func (s *jsonTreeContextState) openStage(prefix, name string) {
+	s.closeStage()
+
+	s.stageKey = len(s.dst)
+	if s.stageKey > 0 && s.dst[s.stageKey-1] != '{' {
+		s.dst = append(s.dst, ',')
+	}
+	s.dst = append(s.dst, '"')
+	s.dst = append(s.dst, prefix...)
+	s.dst = appendJSONStringBody(s.dst, name)
+	s.dst = append(s.dst, `":{`...)
+	s.stageBody = len(s.dst)
+	s.open = true
+	s.empty = true
+}
+
+// closeStage closes current stage, stages without values are dropped like slog does with empty groups.
+func (s *jsonTreeContextState) closeStage() {
+	if !s.open {
+		return
+	}
+
+	// Все спецификации слоя идут одним значением, чтобы не повторять ключ.
+	if len(s.specs) > 0 {
+		s.appendAttr("@spec", slog.AnyValue(s.specs))
+		s.specs = s.specs[:0]
+	}
+	if s.empty {
+		s.dst = s.dst[:s.stageKey]
+	} else {
+		s.dst = append(s.dst, '}')
+	}
+	s.open = false
+}
+
+func (s *jsonTreeContextState) appendAttr(key string, value slog.Value) {
+	n := len(s.dst)
+	s.dst = appendJSONAttr(s.dst, key, value, !s.empty)
+	if len(s.dst) > n {
+		s.empty = false
+	}
+}
+
+// insertLocation puts location as the first value of the stage.
+func (s *jsonTreeContextState) insertLocation(loc string) {
+	n := len(s.dst)
+	s.dst = appendJSONAttr(s.dst, "@location", slog.StringValue(loc), false)
+	if s.empty {
+		s.empty = false
+		return
+	}
+
+	// Переносим локацию в начало уже записанных значений.
+	s.dst = append(s.dst, ',')
+	body := s.dst[s.stageBody:]
+	mid := n - s.stageBody
+	slices.Reverse(body[:mid])
+	slices.Reverse(body[mid:])
+	slices.Reverse(body)
+}
+
+// jsonFlatContextState writes values in the first pass and locations, collected into
+// the @locations object after all values, in the second one.
+type jsonFlatContextState struct {
+	dst []byte
+	// Имя слоя хранится частями, чтобы не склеивать строки без нужды.
+	prefix    string
+	name      string
+	locations int
+	locPass   bool
+	specs     []string
+}
+
+func (s *jsonFlatContextState) feed(attrs []errorAttr) {
+	for _, attr := range attrs {
+		switch attr.kind {
+		case errorAttrKindRemote:
+			continue
+		case errorAttrKindMarker:
+			if showSpecs && !s.locPass {
+				s.specs = append(s.specs, specValue(attr.mark()).String())
+			}
+			continue
+		case errorAttrKindNew:
+			s.prefix, s.name = "NEW: ", attr.key
+		case errorAttrKindOutterNew:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.prefix, s.name = "NEW: ", attr.key
+		case errorAttrKindWrap:
+			s.prefix, s.name = "WRAP: ", attr.key
+		case errorAttrKindOutterWrap:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.prefix, s.name = "WRAP: ", attr.key
+		case errorAttrKindJust:
+			s.prefix, s.name = "CTX", ""
+		case errorAttrKindOutterJust:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+			s.prefix, s.name = "CTX", ""
+		case errorAttrKindPhantomJust:
+			// Рекурсивный спуск.
+			s.feed(innerAttrs(attr))
+		case errorAttrKindLoc:
+			if !s.locPass {
+				s.locations++
+				continue
+			}
+
+			if s.dst[len(s.dst)-1] != '{' {
+				s.dst = append(s.dst, ',')
+			}
+			s.dst = append(s.dst, '"')
+			s.dst = append(s.dst, s.prefix...)
+			s.dst = appendJSONStringBody(s.dst, s.name)
+			s.dst = append(s.dst, `":`...)
+			s.dst = appendJSONString(s.dst, trimLocation(attr.value.String()))
+		default:
+			if !s.locPass {
+				s.dst = appendJSONAttr(s.dst, attr.key, attr.value, s.dst[len(s.dst)-1] != '{')
+			}
+		}
+
+		if showSpecs && !s.locPass {
+			for _, mark := range layerSentinelSpecs(attr) {
+				s.specs = append(s.specs, specValue(mark).String())
+			}
+		}
+	}
+}
+
+// appendSpecs writes specs of all layers as a single @spec value.
+func (s *jsonFlatContextState) appendSpecs() {
+	if len(s.specs) > 0 {
+		s.dst = appendJSONAttr(s.dst, "@spec", slog.AnyValue(s.specs), s.dst[len(s.dst)-1] != '{')
+	}
+}
+
+func (s *jsonFlatContextState) appendLocations(attrs []errorAttr) []byte {
+	if s.locations == 0 {
+		return s.dst
+	}
+
+	if s.dst[len(s.dst)-1] != '{' {
+		s.dst = append(s.dst, ',')
+	}
+	s.dst = append(s.dst, `"@locations":{`...)
+	s.locPass = true
+	s.feed(attrs)
+	return append(s.dst, '}')
+}
+
+// innerAttrs returns context of the error wrapped by the attr.
+func innerAttrs(attr errorAttr) []errorAttr {
+	if e, ok := attr.value.Any().(error); ok {
+		nerr, ok := AsType[*Error](e)
+		if ok {
+			return nerr.attrs
+		}
+	}
+
+	return nil
+}
+
+// appendJSONAttr appends "key":value, preceded by a comma if sep is set. Empty attributes
+// and groups are skipped, attributes of groups with empty keys are inlined, like [slog.JSONHandler] does.
+func appendJSONAttr(dst []byte, key string, value slog.Value, sep bool) []byte {
+	value = value.Resolve()
+	if key == "" && value.Kind() == slog.KindAny && value.Any() == nil {
+		return dst
+	}
+
+	if value.Kind() == slog.KindGroup {
+		attrs := value.Group()
+		if len(attrs) == 0 {
+			return dst
+		}
+		if key == "" {
+			for _, a := range attrs {
+				n := len(dst)
+				dst = appendJSONAttr(dst, a.Key, a.Value, sep)
+				sep = sep || len(dst) > n
+			}
+			return dst
+		}
+
+		start := len(dst)
+		if sep {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, key)
+		dst = append(dst, ":{"...)
+		body := len(dst)
+		inner := false
+		for _, a := range attrs {
+			dst = appendJSONAttr(dst, a.Key, a.Value, inner)
+			inner = len(dst) > body
+		}
+		if !inner {
+			return dst[:start]
+		}
+		return append(dst, '}')
+	}
+
+	if sep {
+		dst = append(dst, ',')
+	}
+	dst = appendJSONString(dst, key)
+	dst = append(dst, ':')
+	return appendJSONValue(dst, value)
+}
+
+// appendJSONValue appends value of a non-group kind encoded the way [slog.JSONHandler] does.
+func appendJSONValue(dst []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendJSONString(dst, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(dst, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(dst, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return appendJSONFloat(dst, v.Float64())
+	case slog.KindBool:
+		return strconv.AppendBool(dst, v.Bool())
+	case slog.KindDuration:
+		return strconv.AppendInt(dst, int64(v.Duration()), 10)
+	case slog.KindTime:
+		dst = append(dst, '"')
+		dst = v.Time().AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"')
+	default:
+		a := v.Any()
+		if b, ok := a.([]byte); ok {
+			dst = append(dst, '"')
+			dst = base64.StdEncoding.AppendEncode(dst, b)
+			return append(dst, '"')
+		}
+
+		_, isMarshaler := a.(json.Marshaler)
+		if err, ok := a.(error); ok && !isMarshaler {
+			return appendJSONString(dst, err.Error())
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(a); err != nil {
+			return appendJSONString(dst, "!ERROR:"+err.Error())
+		}
+		return append(dst, bytes.TrimRight(buf.Bytes(), "\n")...)
+	}
+}
+
+// appendJSONFloat formats floats like encoding/json does.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(dst, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst
+}
+
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendJSONStringBody(dst, s)
+	return append(dst, '"')
+}
+
+const jsonHex = "0123456789abcdef"
+
+// appendJSONStringBody escapes the string like [slog.JSONHandler] does: HTML characters are kept,
+// bytes of invalid UTF-8 are replaced with U+FFFD, line and paragraph separators are escaped.
+func appendJSONStringBody(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', jsonHex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+
+	return append(dst, s[start:]...)
+}
@@ -0,0 +1,190 @@
+package errors_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sirkon/errors"
+)
+
+func ExampleError_AppendJSON() {
+	err := errors.New("connection refused").Int("port", 5432)
+	err = errors.Wrap(err, "connect").Str("db", "users")
+
+	fmt.Println(string(err.AppendJSON(nil, errors.ContextTree)))
+	fmt.Println(string(err.AppendJSON(nil, errors.ContextFlat)))
+
+	// Output:
+	// {"NEW: connection refused":{"port":5432},"WRAP: connect":{"db":"users"}}
+	// {"port":5432,"db":"users"}
+}
+
+type jsonParityValuer struct{}
+
+func (jsonParityValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", "<42>"), slog.Group("empty"), slog.Group("", slog.Int("inlined", 1)))
+}
+
+func jsonParityErrors() map[string]*errors.Error {
+	errs := map[string]*errors.Error{}
+
+	errs["kinds"] = errors.New("kinds \"quoted\"").
+		Bool("bool", true).
+		Int("int", -1).
+		U64("uint", math.MaxUint64).
+		F64("pi", math.Pi).
+		F64("small", 1e-9).
+		F64("large", 1e21).
+		F64("whole", 3).
+		Str("str", "line\nbreak\t\"q\" \\ <html> & \x01 \u2028 bad\xff").
+		Bytes("bytes", []byte{0, 1, 2}).
+		Strs("strs", []string{"a", "b"}).
+		Any("duration", time.Second).
+		Any("time", time.Date(2024, 3, 1, 12, 0, 0, 5, time.UTC)).
+		Any("error", io.EOF).
+		Any("valuer", jsonParityValuer{}).
+		Any("map", map[string]int{"b": 2, "a": 1}).
+		Any("nil", nil)
+
+	errs["layers"] = errors.Just(
+		errors.Wrap(
+			errors.Wrap(errors.New("inner").Int("code", 1), "no context"),
+			"middle",
+		).Str("op", "read"),
+	).F64("e", math.E)
+
+	foreign := fmt.Errorf("foreign: %w", errors.New("deep").Str("k", "v"))
+	errs["foreign"] = errors.Wrap(foreign, "outer").Int("n", 1)
+	errs["foreign-just"] = errors.Just(foreign).Int("n", 2)
+	errs["foreign-from"] = errors.From(foreign).Int("n", 3)
+	errs["spec"] = errors.Wrap(errors.Spec(errors.New("marked").Int("n", 3), new(1)), "wrap").Int("m", 4)
+	errs["specs"] = errors.Spec(errors.Spec(errors.Wrap(
+		errors.Spec(errors.Wrap(errors.NewSentinel("sentinel", errors.WithSpec(time.Second)), "inner"), new(1)),
+		"outer",
+	), new(2)), new(3))
+	errs["sentinel-spec"] = errors.DropSpec[int](errors.Wrap(
+		errors.NewSentinel("sentinel", errors.WithSpec(time.Second)),
+		"wrap",
+	).Int("n", 5))
+
+	data, _ := json.Marshal(errs["layers"])
+	remote, _ := errors.Decode(data)
+	errs["remote"] = errors.Wrap(remote, "local").Int("n", 6)
+
+	errors.InsertLocations()
+	defer errors.DoNotInsertLocations()
+	errs["locations"] = errors.Wrap(errors.New("located").Int("n", 5), "wrap")
+	errs["location-only"] = errors.Just(errors.New("located"))
+
+	return errs
+}
+
+func TestAppendJSONParity(t *testing.T) {
+	t.Run("specs-hidden", testAppendJSONParity)
+	t.Run("specs-shown", func(t *testing.T) {
+		errors.ShowSpecs()
+		defer errors.HideSpecs()
+		testAppendJSONParity(t)
+	})
+}
+
+func testAppendJSONParity(t *testing.T) {
+	for name, err := range jsonParityErrors() {
+		for _, mode := range []errors.ContextMode{errors.ContextTree, errors.ContextFlat} {
+			attrs := errors.SLogTreeContext(err)
+			if mode == errors.ContextFlat {
+				attrs = errors.SLogFlatContext(err)
+			}
+
+			got := string(err.AppendJSON(nil, mode))
+			if !json.Valid([]byte(got)) {
+				t.Errorf("%s mode %d: invalid JSON %s", name, mode, got)
+			}
+
+			want, ok := slogJSONAttr(t, slog.GroupAttrs("ctx", attrs...))
+			if !ok {
+				// slog.JSONHandler misses commas around empty groups, nothing to compare with.
+				continue
+			}
+			if want == "" {
+				want = "{}"
+			}
+			if got != want {
+				t.Errorf("%s mode %d:\ngot  %s\nwant %s", name, mode, got, want)
+			}
+
+			if marshaled, _ := slogJSONAttr(t, slog.Any("ctx", errors.JSONContext(err, mode))); marshaled != want {
+				t.Errorf("%s mode %d: JSONContext gives\n%s\nwant\n%s", name, mode, marshaled, want)
+			}
+		}
+	}
+}
+
+// slogJSONAttr returns the value of the attr encoded by [slog.JSONHandler].
+func slogJSONAttr(t *testing.T, attr slog.Attr) (string, bool) {
+	var buf bytes.Buffer
+	record := slog.NewRecord(time.Time{}, slog.LevelError, "", 0)
+	record.AddAttrs(attr)
+	if err := slog.NewJSONHandler(&buf, nil).Handle(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	var line map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		return "", false
+	}
+
+	return string(line[attr.Key]), true
+}
+
+func BenchmarkContextJSON(b *testing.B) {
+	err := errors.New("this is an error").
+		Bytes("bytes", []byte{1, 2, 3}).
+		Str("text-bytes", "Hello World!")
+	err = errors.Wrap(err, "check error").
+		Int("count", 333).
+		Bool("is-wrap-layer", true)
+	err = errors.Just(err).
+		F64("pi", math.Pi).
+		F64("e", math.E)
+
+	for _, mode := range []errors.ContextMode{errors.ContextTree, errors.ContextFlat} {
+		name := "tree"
+		if mode == errors.ContextFlat {
+			name = "flat"
+		}
+
+		b.Run(name+"/slog-attrs", func(b *testing.B) {
+			b.ReportAllocs()
+			log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			for b.Loop() {
+				attrs := errors.SLogTreeContext(err)
+				if mode == errors.ContextFlat {
+					attrs = errors.SLogFlatContext(err)
+				}
+				log.Error("failed", slog.GroupAttrs("@err", attrs...))
+			}
+		})
+		b.Run(name+"/json-context", func(b *testing.B) {
+			b.ReportAllocs()
+			log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+			for b.Loop() {
+				log.Error("failed", slog.Any("@err", errors.JSONContext(err, mode)))
+			}
+		})
+		b.Run(name+"/append-json", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := make([]byte, 0, 1024)
+			for b.Loop() {
+				buf = err.AppendJSON(buf[:0], mode)
+			}
+		})
+	}
+}
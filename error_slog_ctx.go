@@ -15,6 +15,9 @@ func SLogTreeContext(err *Error) []slog.Attr {
 func SLogFlatContext(err *Error) []slog.Attr {
 	s := newSlogFlatContextState()
 	s.feed(err.attrs)
+	if len(s.specs) > 0 {
+		s.ctx = append(s.ctx, slog.Any("@spec", s.specs))
+	}
 	s.ctx = append(s.ctx, slog.GroupAttrs("@locations", s.pos...))
 	return s.ctx
 }
@@ -25,6 +28,7 @@ type slogTreeContextState struct {
 	hasPos       bool
 	hasSomething bool
 	name         string
+	specs        []string
 }
 
 func newSlogTreeContextState() *slogTreeContextState {
@@ -38,11 +42,26 @@ func (s *slogTreeContextState) feed(attrs []errorAttr) {
 	for _, attr := range attrs {
 		switch attr.kind {
 		case errorAttrKindMarker:
+			if showSpecs {
+				s.specs = append(s.specs, specValue(attr.mark()).String())
+			}
 			continue
 		case errorAttrKindNew:
 			s.closeStage()
 			s.name = "NEW: " + attr.key
 
+		case errorAttrKindOutterNew:
+			// Рекурсивный спуск.
+			if e, ok := attr.value.Any().(error); ok {
+				nerr, ok := AsType[*Error](e)
+				if ok {
+					s.feed(nerr.attrs)
+				}
+			}
+
+			s.closeStage()
+			s.name = "NEW: " + attr.key
+
 		case errorAttrKindWrap:
 			s.closeStage()
 			s.name = "WRAP: " + attr.key
@@ -85,16 +104,24 @@ func (s *slogTreeContextState) feed(attrs []errorAttr) {
 			}
 
 		case errorAttrKindLoc:
-			s.stage[0] = slog.Attr{
-				Key:   "@location",
+			s.stage[0] = slog.String("@location", trimLocation(attr.value.String()))
+		case errorAttrKindRemote:
+			s.stage = append(s.stage, slog.Attr{
+				Key:   "@remote",
 				Value: attr.value,
-			}
+			})
 		default:
 			s.stage = append(s.stage, slog.Attr{
 				Key:   attr.key,
 				Value: attr.value,
 			})
 		}
+
+		if showSpecs {
+			for _, mark := range layerSentinelSpecs(attr) {
+				s.specs = append(s.specs, specValue(mark).String())
+			}
+		}
 	}
 
 	s.closeStage()
@@ -105,6 +132,11 @@ func (s *slogTreeContextState) closeStage() {
 		return
 	}
 
+	// Все спецификации слоя идут одним значением, чтобы не повторять ключ.
+	if len(s.specs) > 0 {
+		s.stage = append(s.stage, slog.Any("@spec", s.specs))
+		s.specs = nil
+	}
 	s.stages = append(s.stages, slog.GroupAttrs(s.name, s.stage...))
 	s.stage = s.stage[len(s.stage):]
 	if cap(s.stage) > 0 {
@@ -116,9 +148,10 @@ func (s *slogTreeContextState) closeStage() {
 }
 
 type slogFlatContextState struct {
-	ctx  []slog.Attr
-	pos  []slog.Attr
-	name string
+	ctx   []slog.Attr
+	pos   []slog.Attr
+	name  string
+	specs []string
 }
 
 func newSlogFlatContextState() *slogFlatContextState {
@@ -131,10 +164,24 @@ func newSlogFlatContextState() *slogFlatContextState {
 func (s *slogFlatContextState) feed(attrs []errorAttr) {
 	for _, attr := range attrs {
 		switch attr.kind {
+		case errorAttrKindRemote:
+			continue
 		case errorAttrKindMarker:
+			if showSpecs {
+				s.specs = append(s.specs, specValue(attr.mark()).String())
+			}
 			continue
 		case errorAttrKindNew:
 			s.name = "NEW: " + attr.key
+		case errorAttrKindOutterNew:
+			// Рекурсивный спуск.
+			if e, ok := attr.value.Any().(error); ok {
+				nerr, ok := AsType[*Error](e)
+				if ok {
+					s.feed(nerr.attrs)
+				}
+			}
+			s.name = "NEW: " + attr.key
 		case errorAttrKindWrap:
 			s.name = "WRAP: " + attr.key
 		case errorAttrKindOutterWrap:
@@ -166,12 +213,18 @@ func (s *slogFlatContextState) feed(attrs []errorAttr) {
 				}
 			}
 		case errorAttrKindLoc:
-			s.pos = append(s.pos, slog.String(s.name, attr.value.String()))
+			s.pos = append(s.pos, slog.String(s.name, trimLocation(attr.value.String())))
 		default:
 			s.ctx = append(s.ctx, slog.Attr{
 				Key:   attr.key,
 				Value: attr.value,
 			})
 		}
+
+		if showSpecs {
+			for _, mark := range layerSentinelSpecs(attr) {
+				s.specs = append(s.specs, specValue(mark).String())
+			}
+		}
 	}
 }
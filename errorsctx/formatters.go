@@ -0,0 +1,226 @@
+package errorsctx
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLFormatter renders URLs as trees of their parts with decoded query parameters.
+// Passwords of the user info are never shown.
+func URLFormatter() ValueFormatter {
+	return ValueFormatterFunc(func(key string, value slog.Value) (*TreeNode, bool) {
+		if value.Kind() != slog.KindString {
+			return nil, false
+		}
+
+		u, err := url.Parse(value.String())
+		if err != nil || u.Scheme == "" && u.Host == "" {
+			return nil, false
+		}
+
+		node := &TreeNode{Key: key, Kind: KindGroup}
+		addChild := func(k, v string) {
+			if v != "" {
+				node.Children = append(node.Children, &TreeNode{Key: k, Value: v, Kind: KindString})
+			}
+		}
+		addChild("scheme", u.Scheme)
+		if u.User != nil {
+			addChild("user", u.User.Username())
+		}
+		addChild("host", u.Host)
+		addChild("path", u.Path)
+
+		if query := u.Query(); len(query) > 0 {
+			q := &TreeNode{Key: "query", Kind: KindGroup}
+			for _, k := range slices.Sorted(maps.Keys(query)) {
+				for _, v := range query[k] {
+					q.Children = append(q.Children, &TreeNode{Key: k, Value: v, Kind: KindString})
+				}
+			}
+			node.Children = append(node.Children, q)
+		}
+		addChild("fragment", u.Fragment)
+
+		return node, true
+	})
+}
+
+// PEMFormatter summarizes PEM encoded data: certificates are shown with their subject,
+// issuer, validity and names, other blocks with their type and size.
+func PEMFormatter() ValueFormatter {
+	return ValueFormatterFunc(func(key string, value slog.Value) (*TreeNode, bool) {
+		var data []byte
+		switch value.Kind() {
+		case slog.KindString:
+			data = []byte(value.String())
+		case slog.KindAny:
+			b, ok := value.Any().([]byte)
+			if !ok {
+				return nil, false
+			}
+			data = b
+		default:
+			return nil, false
+		}
+
+		node := &TreeNode{Key: key, Kind: KindArray}
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+
+			node.Children = append(node.Children, pemBlockNode("["+strconv.Itoa(len(node.Children))+"]", block))
+		}
+		if len(node.Children) == 0 {
+			return nil, false
+		}
+
+		if len(node.Children) == 1 {
+			node.Children[0].Key = key
+			return node.Children[0], true
+		}
+
+		return node, true
+	})
+}
+
+func pemBlockNode(key string, block *pem.Block) *TreeNode {
+	node := &TreeNode{Key: key, Kind: KindGroup}
+	addChild := func(k, v string) {
+		node.Children = append(node.Children, &TreeNode{Key: k, Value: v, Kind: KindString})
+	}
+	addChild("type", block.Type)
+
+	if block.Type != "CERTIFICATE" {
+		node.Children = append(node.Children, &TreeNode{
+			Key:   "size",
+			Value: strconv.Itoa(len(block.Bytes)),
+			Kind:  KindNumber,
+		})
+		return node
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		node.Children = append(node.Children, &TreeNode{Key: "error", Value: err.Error(), Kind: KindErrorText})
+		return node
+	}
+
+	addChild("subject", cert.Subject.String())
+	addChild("issuer", cert.Issuer.String())
+	addChild("serial", hex.EncodeToString(cert.SerialNumber.Bytes()))
+	addChild("not-before", cert.NotBefore.Format(time.RFC3339))
+	addChild("not-after", cert.NotAfter.Format(time.RFC3339))
+	if len(cert.DNSNames) > 0 {
+		addChild("dns-names", strings.Join(cert.DNSNames, ", "))
+	}
+
+	return node
+}
+
+// SQLFormatter renders SQL queries as multiline text with every major clause on its own line.
+func SQLFormatter() ValueFormatter {
+	return ValueFormatterFunc(func(key string, value slog.Value) (*TreeNode, bool) {
+		if value.Kind() != slog.KindString {
+			return nil, false
+		}
+
+		return &TreeNode{
+			Key:   key,
+			Value: formatSQL(value.String()),
+			Kind:  KindText,
+		}, true
+	})
+}
+
+var sqlClauses = []string{
+	"SELECT", "FROM", "WHERE", "GROUP BY", "ORDER BY", "HAVING", "LIMIT", "OFFSET",
+	"LEFT JOIN", "RIGHT JOIN", "INNER JOIN", "FULL JOIN", "CROSS JOIN", "JOIN",
+	"UNION", "INSERT INTO", "VALUES", "UPDATE", "SET", "DELETE FROM", "RETURNING", "ON CONFLICT",
+}
+
+// formatSQL puts major SQL clauses on separate lines. Quoted literals and identifiers are kept intact.
+func formatSQL(query string) string {
+	words := splitSQL(query)
+
+	var res strings.Builder
+	for i := 0; i < len(words); i++ {
+		clause, n := matchSQLClause(words[i:])
+		if clause != "" {
+			if res.Len() > 0 {
+				res.WriteByte('\n')
+			}
+			res.WriteString(clause)
+			i += n - 1
+			continue
+		}
+
+		if res.Len() > 0 {
+			res.WriteByte(' ')
+		}
+		res.WriteString(words[i])
+	}
+
+	return res.String()
+}
+
+func matchSQLClause(words []string) (string, int) {
+	for _, clause := range sqlClauses {
+		parts := strings.Fields(clause)
+		if len(parts) > len(words) {
+			continue
+		}
+
+		matched := true
+		for i, part := range parts {
+			if !strings.EqualFold(words[i], part) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return clause, len(parts)
+		}
+	}
+
+	return "", 0
+}
+
+// splitSQL splits query by whitespaces outside of quotes.
+func splitSQL(query string) []string {
+	var words []string
+	var quote byte
+	start := 0
+	for i := range len(query) {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if i > start {
+				words = append(words, query[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(query) {
+		words = append(words, query[start:])
+	}
+
+	return words
+}
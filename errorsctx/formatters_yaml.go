@@ -0,0 +1,468 @@
+package errorsctx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// YAMLFormatter renders YAML documents as trees. It supports block and flow mappings and
+// sequences, quoted and plain scalars and literal block scalars. Documents with anchors,
+// aliases, tags, folded scalars or several documents, as well as plain scalars and anything
+// it fails to parse, are left to other formatters and the renderer itself.
+func YAMLFormatter() ValueFormatter {
+	return ValueFormatterFunc(func(key string, value slog.Value) (*TreeNode, bool) {
+		var text string
+		switch value.Kind() {
+		case slog.KindString:
+			text = value.String()
+		case slog.KindAny:
+			b, ok := value.Any().([]byte)
+			if !ok {
+				return nil, false
+			}
+			text = string(b)
+		default:
+			return nil, false
+		}
+
+		p, ok := newYAMLParser(text)
+		if !ok {
+			return nil, false
+		}
+
+		node, ok := p.parseBlock(key, 0)
+		if !ok || p.next() < len(p.lines) {
+			return nil, false
+		}
+		if node.Kind != KindGroup && node.Kind != KindArray {
+			return nil, false
+		}
+
+		return node, true
+	})
+}
+
+type yamlLine struct {
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func newYAMLParser(text string) (*yamlParser, bool) {
+	p := &yamlParser{}
+	started := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.TrimSpace(trimmed) == "" {
+			p.lines = append(p.lines, yamlLine{})
+			continue
+		}
+		if trimmed[0] == '\t' {
+			// Табы в отступах YAML запрещает.
+			return nil, false
+		}
+
+		switch strings.TrimRight(trimmed, " \t") {
+		case "---":
+			if started {
+				return nil, false
+			}
+			continue
+		case "...":
+			continue
+		}
+		if trimmed[0] != '#' {
+			started = true
+		}
+
+		p.lines = append(p.lines, yamlLine{
+			indent: len(line) - len(trimmed),
+			text:   strings.TrimRight(trimmed, " \t"),
+		})
+	}
+
+	return p, true
+}
+
+// next skips blank and comment lines and returns the position of the next meaningful line.
+func (p *yamlParser) next() int {
+	for p.pos < len(p.lines) {
+		text := p.lines[p.pos].text
+		if text != "" && text[0] != '#' {
+			break
+		}
+		p.pos++
+	}
+
+	return p.pos
+}
+
+// parseBlock parses the block node starting at the current line with at least the given indentation.
+func (p *yamlParser) parseBlock(key string, minIndent int) (*TreeNode, bool) {
+	if p.next() >= len(p.lines) {
+		return &TreeNode{Key: key, Kind: KindNull, Value: "null"}, true
+	}
+
+	line := p.lines[p.pos]
+	if line.indent < minIndent {
+		return &TreeNode{Key: key, Kind: KindNull, Value: "null"}, true
+	}
+
+	if isYAMLSequenceItem(line.text) {
+		return p.parseSequence(key, line.indent)
+	}
+	if _, _, ok := splitYAMLKey(line.text); ok {
+		return p.parseMapping(key, line.indent)
+	}
+
+	p.pos++
+	return parseYAMLInline(key, stripYAMLComment(line.text))
+}
+
+func (p *yamlParser) parseSequence(key string, indent int) (*TreeNode, bool) {
+	node := &TreeNode{Key: key, Kind: KindArray}
+	for p.next() < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isYAMLSequenceItem(line.text) {
+			break
+		}
+
+		itemKey := indexKey(len(node.Children))
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var item *TreeNode
+		var ok bool
+		if rest == "" || rest[0] == '#' {
+			p.pos++
+			item, ok = p.parseBlock(itemKey, indent+1)
+		} else {
+			// Содержимое элемента продолжается в той же строке, отступ считаем от него.
+			p.lines[p.pos] = yamlLine{
+				indent: indent + len(line.text) - len(rest),
+				text:   rest,
+			}
+			item, ok = p.parseBlock(itemKey, indent+1)
+		}
+		if !ok {
+			return nil, false
+		}
+		node.Children = append(node.Children, item)
+	}
+
+	return node, true
+}
+
+func (p *yamlParser) parseMapping(key string, indent int) (*TreeNode, bool) {
+	node := &TreeNode{Key: key, Kind: KindGroup}
+	for p.next() < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent {
+			if line.indent > indent {
+				return nil, false
+			}
+			break
+		}
+
+		k, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, false
+		}
+		p.pos++
+
+		rest = stripYAMLComment(rest)
+		var child *TreeNode
+		switch {
+		case rest == "":
+			// Последовательность под ключом может иметь тот же отступ, что и сам ключ.
+			if p.next() < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceItem(p.lines[p.pos].text) {
+				child, ok = p.parseSequence(k, indent)
+			} else {
+				child, ok = p.parseBlock(k, indent+1)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			child, ok = p.parseBlockScalar(k, rest, indent)
+		default:
+			child, ok = parseYAMLInline(k, rest)
+		}
+		if !ok {
+			return nil, false
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node, true
+}
+
+// parseBlockScalar parses literal (|) block scalars. Trailing line breaks are not shown,
+// so chomping indicators are accepted but ignored.
+func (p *yamlParser) parseBlockScalar(key, header string, indent int) (*TreeNode, bool) {
+	if header[0] != '|' || strings.Trim(header[1:], "+-") != "" {
+		// Явно заданные отступы не поддерживаем.
+		return nil, false
+	}
+
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.text != "" && line.indent <= indent {
+			break
+		}
+		if line.text != "" && blockIndent < 0 {
+			blockIndent = line.indent
+		}
+
+		if line.text == "" {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, strings.Repeat(" ", line.indent-blockIndent)+line.text)
+		}
+		p.pos++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return &TreeNode{Key: key, Value: strings.Join(lines, "\n"), Kind: KindText}, true
+}
+
+// parseYAMLInline parses flow collections and scalars.
+func parseYAMLInline(key, text string) (*TreeNode, bool) {
+	s := &yamlFlow{text: text}
+	node, ok := s.parse(key, false)
+	if !ok {
+		return nil, false
+	}
+
+	s.skipSpaces()
+	if s.pos != len(s.text) {
+		return nil, false
+	}
+
+	return node, true
+}
+
+type yamlFlow struct {
+	text string
+	pos  int
+}
+
+func (s *yamlFlow) skipSpaces() {
+	for s.pos < len(s.text) && s.text[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+// parse parses the value, inFlow tells if it is an element of a flow collection.
+func (s *yamlFlow) parse(key string, inFlow bool) (*TreeNode, bool) {
+	s.skipSpaces()
+	if s.pos >= len(s.text) {
+		return &TreeNode{Key: key, Kind: KindNull, Value: "null"}, true
+	}
+
+	switch s.text[s.pos] {
+	case '[':
+		return s.parseCollection(key, ']')
+	case '{':
+		return s.parseCollection(key, '}')
+	case '"', '\'':
+		v, ok := s.parseQuoted()
+		if !ok {
+			return nil, false
+		}
+		return &TreeNode{Key: key, Value: v, Kind: KindString}, true
+	case '&', '*', '!', '@', '`', '%':
+		return nil, false
+	}
+
+	start := s.pos
+	for s.pos < len(s.text) {
+		c := s.text[s.pos]
+		if inFlow && (c == ',' || c == ']' || c == '}' || c == ':' && s.plainColon()) {
+			break
+		}
+		s.pos++
+	}
+
+	return yamlScalarNode(key, strings.TrimRight(s.text[start:s.pos], " ")), true
+}
+
+// plainColon checks if the colon at the current position separates a key from its value.
+func (s *yamlFlow) plainColon() bool {
+	return s.pos+1 == len(s.text) || strings.IndexByte(" ,]}", s.text[s.pos+1]) >= 0
+}
+
+func (s *yamlFlow) parseCollection(key string, closing byte) (*TreeNode, bool) {
+	node := &TreeNode{Key: key, Kind: KindArray}
+	if closing == '}' {
+		node.Kind = KindGroup
+	}
+
+	s.pos++
+	for {
+		s.skipSpaces()
+		if s.pos >= len(s.text) {
+			return nil, false
+		}
+		if s.text[s.pos] == closing {
+			s.pos++
+			return node, true
+		}
+
+		childKey := indexKey(len(node.Children))
+		start := s.pos
+		if closing == '}' {
+			k, ok := s.parse("", true)
+			if !ok || k.Kind == KindGroup || k.Kind == KindArray {
+				return nil, false
+			}
+			childKey = k.Value
+			s.skipSpaces()
+			if s.pos >= len(s.text) || s.text[s.pos] != ':' {
+				return nil, false
+			}
+			s.pos++
+		}
+
+		child, ok := s.parse(childKey, true)
+		if !ok || s.pos == start {
+			// Элемент, не занявший ни одного символа, означает мусор вроде [:], на нём парсер зациклится.
+			return nil, false
+		}
+		node.Children = append(node.Children, child)
+
+		s.skipSpaces()
+		switch {
+		case s.pos >= len(s.text):
+			return nil, false
+		case s.text[s.pos] == ',':
+			s.pos++
+		case s.text[s.pos] != closing:
+			return nil, false
+		}
+	}
+}
+
+func (s *yamlFlow) parseQuoted() (string, bool) {
+	quote := s.text[s.pos]
+	for i := s.pos + 1; i < len(s.text); i++ {
+		switch c := s.text[i]; {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(s.text) && s.text[i+1] == '\'' {
+				i++
+				continue
+			}
+			v := strings.ReplaceAll(s.text[s.pos+1:i], "''", "'")
+			s.pos = i + 1
+			return v, true
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '"' && c == '"':
+			var v string
+			if err := json.Unmarshal([]byte(s.text[s.pos:i+1]), &v); err != nil {
+				return "", false
+			}
+			s.pos = i + 1
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+func yamlScalarNode(key, text string) *TreeNode {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return &TreeNode{Key: key, Kind: KindNull, Value: "null"}
+	case "true", "True", "TRUE":
+		return &TreeNode{Key: key, Kind: KindBool, Value: "true"}
+	case "false", "False", "FALSE":
+		return &TreeNode{Key: key, Kind: KindBool, Value: "false"}
+	}
+
+	if isYAMLNumber(text) {
+		return &TreeNode{Key: key, Kind: KindNumber, Value: text}
+	}
+
+	return &TreeNode{Key: key, Kind: KindString, Value: text}
+}
+
+// isYAMLNumber checks if the plain scalar is a decimal number.
+func isYAMLNumber(text string) bool {
+	if strings.Trim(text, "0123456789+-.eE") != "" {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value" line of a block mapping.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text == "" || strings.IndexByte("[{#&*!|>%@`", text[0]) >= 0 {
+		return "", "", false
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		s := &yamlFlow{text: text}
+		k, ok := s.parseQuoted()
+		if !ok || s.pos >= len(text) || text[s.pos] != ':' {
+			return "", "", false
+		}
+		rest = text[s.pos+1:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return k, strings.TrimLeft(rest, " "), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case ':':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				return strings.TrimRight(text[:i], " "), strings.TrimLeft(text[i+1:], " "), i > 0
+			}
+		case '#':
+			if i > 0 && text[i-1] == ' ' {
+				return "", "", false
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// stripYAMLComment removes the trailing comment outside of quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" [{,:", text[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || text[i-1] == ' ' {
+				return strings.TrimRight(text[:i], " ")
+			}
+		}
+	}
+
+	return text
+}
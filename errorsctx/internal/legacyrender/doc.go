@@ -0,0 +1,8 @@
+//go:build legacyrender
+
+// Package legacyrender is a frozen copy of the pretty renderer as it was before IR nodes
+// were pooled. It is only built with the legacyrender tag and serves as the baseline for
+// renderer benchmarks:
+//
+//	go test -tags legacyrender -run - -bench SlogPrettyRenderer ./errorsctx
+package legacyrender
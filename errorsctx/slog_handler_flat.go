@@ -3,17 +3,19 @@ package errorsctx
 import (
 	"context"
 	"log/slog"
-
-	"github.com/sirkon/errors"
 )
 
 // SLogHandlerFlat handler for a flat view of an error context.
 type SLogHandlerFlat struct {
 	handler slog.Handler
+	opts    handlerOptions
 }
 
-func NewSLogHandlerFlat(handler slog.Handler) *SLogHandlerFlat {
-	return &SLogHandlerFlat{handler: handler}
+func NewSLogHandlerFlat(handler slog.Handler, opts ...HandlerOption) *SLogHandlerFlat {
+	return &SLogHandlerFlat{
+		handler: handler,
+		opts:    newHandlerOptions(opts),
+	}
 }
 
 func (h *SLogHandlerFlat) Enabled(ctx context.Context, level slog.Level) bool {
@@ -23,47 +25,17 @@ func (h *SLogHandlerFlat) Enabled(ctx context.Context, level slog.Level) bool {
 func (h *SLogHandlerFlat) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &SLogHandlerFlat{
 		handler: h.handler.WithAttrs(attrs),
+		opts:    h.opts,
 	}
 }
 
 func (h *SLogHandlerFlat) WithGroup(name string) slog.Handler {
 	return &SLogHandlerFlat{
 		handler: h.handler.WithGroup(name),
+		opts:    h.opts,
 	}
 }
 
 func (h *SLogHandlerFlat) Handle(ctx context.Context, r slog.Record) error {
-	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
-
-	r.Attrs(func(a slog.Attr) bool {
-		e, ok := a.Value.Any().(error)
-		if !ok {
-			newRecord.AddAttrs(a)
-			return true
-		}
-
-		err, ok := e.(*errors.Error)
-		if !ok {
-			err, ok = errors.AsType[*errors.Error](e)
-			if !ok {
-				newRecord.AddAttrs(a)
-				return true
-			}
-		}
-
-		if a.Key == "" || a.Key == "!BADKEY" {
-			a.Key = "err"
-		}
-
-		// Add error message under a key.
-		newRecord.AddAttrs(slog.String(a.Key, e.Error()))
-
-		// Add context tree as @key.
-		ctx := errors.SLogFlatContext(err)
-		newRecord.AddAttrs(slog.GroupAttrs("@"+a.Key, ctx...))
-		return true
-
-	})
-
-	return h.handler.Handle(ctx, newRecord)
+	return h.handler.Handle(ctx, recordWithErrors(r, ErrorModeFlat, h.opts))
 }
@@ -0,0 +1,187 @@
+package errorsctx
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/sirkon/errors"
+)
+
+// ErrorMode defines how errors are put into log records.
+type ErrorMode int
+
+const (
+	// ErrorModeText puts just the error text under the attribute key.
+	ErrorModeText ErrorMode = iota
+	// ErrorModeFlat puts the error text under the key and its flat context under @key, like [SLogHandlerFlat] does.
+	ErrorModeFlat
+	// ErrorModeTree puts the error text and context tree into the key group, like [SLogHandlerTree] does.
+	ErrorModeTree
+)
+
+func (m ErrorMode) String() string {
+	switch m {
+	case ErrorModeText:
+		return "text"
+	case ErrorModeFlat:
+		return "flat"
+	case ErrorModeTree:
+		return "tree"
+	default:
+		return "ErrorMode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// LevelMode sets error rendering mode for records of the Level and above.
+type LevelMode struct {
+	Level slog.Level
+	Mode  ErrorMode
+}
+
+// DefaultLevelModes renders errors of error records as trees, errors of warnings flat
+// and just error texts for lower levels.
+var DefaultLevelModes = []LevelMode{
+	{Level: slog.LevelError, Mode: ErrorModeTree},
+	{Level: slog.LevelWarn, Mode: ErrorModeFlat},
+}
+
+// SLogHandlerLeveled handler picks error rendering mode by record level.
+type SLogHandlerLeveled struct {
+	handler slog.Handler
+	modes   []LevelMode
+	opts    handlerOptions
+}
+
+// NewSLogHandlerLeveled creates handler [SLogHandlerLeveled]. A record uses the mode of the
+// highest level not exceeding its own level, [ErrorModeText] is used for records below all of them.
+// [DefaultLevelModes] are used when modes are empty. Use [SLogHandlerLeveled.WithOptions] to tune it.
+func NewSLogHandlerLeveled(handler slog.Handler, modes ...LevelMode) *SLogHandlerLeveled {
+	if len(modes) == 0 {
+		modes = DefaultLevelModes
+	}
+
+	modes = slices.Clone(modes)
+	slices.SortStableFunc(modes, func(a, b LevelMode) int {
+		return cmp.Compare(b.Level, a.Level)
+	})
+
+	return &SLogHandlerLeveled{
+		handler: handler,
+		modes:   modes,
+	}
+}
+
+// WithOptions returns a copy of the handler tuned with given options.
+func (h *SLogHandlerLeveled) WithOptions(opts ...HandlerOption) *SLogHandlerLeveled {
+	res := *h
+	for _, opt := range opts {
+		opt(&res.opts)
+	}
+
+	return &res
+}
+
+func (h *SLogHandlerLeveled) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SLogHandlerLeveled) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SLogHandlerLeveled{
+		handler: h.handler.WithAttrs(attrs),
+		modes:   h.modes,
+		opts:    h.opts,
+	}
+}
+
+func (h *SLogHandlerLeveled) WithGroup(name string) slog.Handler {
+	return &SLogHandlerLeveled{
+		handler: h.handler.WithGroup(name),
+		modes:   h.modes,
+		opts:    h.opts,
+	}
+}
+
+// Handle handles errors.
+func (h *SLogHandlerLeveled) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, recordWithErrors(r, h.mode(r.Level), h.opts))
+}
+
+func (h *SLogHandlerLeveled) mode(level slog.Level) ErrorMode {
+	for _, m := range h.modes {
+		if level >= m.Level {
+			return m.Mode
+		}
+	}
+
+	return ErrorModeText
+}
+
+// recordWithErrors returns a copy of the record with error attributes rendered in the given mode.
+func recordWithErrors(r slog.Record, mode ErrorMode, opts handlerOptions) slog.Record {
+	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		e, ok := a.Value.Any().(error)
+		if !ok {
+			newRecord.AddAttrs(a)
+			return true
+		}
+
+		err, ok := e.(*errors.Error)
+		if !ok {
+			err, ok = errors.AsType[*errors.Error](e)
+			if !ok {
+				newRecord.AddAttrs(a)
+				return true
+			}
+		}
+
+		if a.Key == "" || a.Key == "!BADKEY" {
+			a.Key = "err"
+		}
+
+		switch {
+		case mode == ErrorModeTree && opts.directJSON:
+			text := slog.String("@text", e.Error())
+			if ctx, ok := directContext(err, errors.ContextTree); ok {
+				newRecord.AddAttrs(slog.GroupAttrs(a.Key, text, slog.Any("@context", ctx)))
+			} else {
+				newRecord.AddAttrs(slog.GroupAttrs(a.Key, text))
+			}
+		case mode == ErrorModeTree:
+			// Add error as err-key.@text and err-key.@context.
+			newRecord.AddAttrs(slog.GroupAttrs(
+				a.Key,
+				slog.String("@text", e.Error()),
+				slog.GroupAttrs("@context", errors.SLogTreeContext(err)...),
+			))
+		case mode == ErrorModeFlat && opts.directJSON:
+			newRecord.AddAttrs(slog.String(a.Key, e.Error()))
+			if ctx, ok := directContext(err, errors.ContextFlat); ok {
+				newRecord.AddAttrs(slog.Any("@"+a.Key, ctx))
+			}
+		case mode == ErrorModeFlat:
+			// Add error message under a key and context tree as @key.
+			newRecord.AddAttrs(
+				slog.String(a.Key, e.Error()),
+				slog.GroupAttrs("@"+a.Key, errors.SLogFlatContext(err)...),
+			)
+		default:
+			newRecord.AddAttrs(slog.String(a.Key, e.Error()))
+		}
+		return true
+	})
+
+	return newRecord
+}
+
+// directContext encodes the error context into JSON. Empty contexts are reported to be
+// skipped, like slog skips empty groups.
+func directContext(err *errors.Error, mode errors.ContextMode) (json.RawMessage, bool) {
+	data := err.AppendJSON(make([]byte, 0, 256), mode)
+	return data, len(data) > len("{}")
+}
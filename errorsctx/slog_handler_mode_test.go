@@ -0,0 +1,103 @@
+package errorsctx_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
+)
+
+func TestSLogHandlerLeveled(t *testing.T) {
+	err := errors.Wrap(errors.New("connection refused").Int("port", 5432), "connect").Str("db", "users")
+
+	for _, tt := range []struct {
+		name  string
+		err   error
+		modes []errorsctx.LevelMode
+		level slog.Level
+		want  string
+	}{
+		{
+			name:  "error-tree",
+			level: slog.LevelError,
+			want:  `{"err":{"@text":"connect: connection refused","@context":{"NEW: connection refused":{"port":5432},"WRAP: connect":{"db":"users"}}}}`,
+		},
+		{
+			name:  "warn-flat",
+			level: slog.LevelWarn,
+			want:  `{"err":"connect: connection refused","@err":{"port":5432,"db":"users"}}`,
+		},
+		{
+			name:  "info-text",
+			level: slog.LevelInfo,
+			want:  `{"err":"connect: connection refused"}`,
+		},
+		{
+			name: "custom",
+			modes: []errorsctx.LevelMode{
+				{Level: slog.LevelDebug, Mode: errorsctx.ErrorModeFlat},
+				{Level: slog.LevelWarn, Mode: errorsctx.ErrorModeText},
+			},
+			level: slog.LevelInfo,
+			want:  `{"err":"connect: connection refused","@err":{"port":5432,"db":"users"}}`,
+		},
+		{
+			name:  "error-tree-no-context",
+			err:   errors.Wrap(io.EOF, "read"),
+			level: slog.LevelError,
+			want:  `{"err":{"@text":"read: EOF"}}`,
+		},
+		{
+			name:  "warn-flat-no-context",
+			err:   errors.Wrap(io.EOF, "read"),
+			level: slog.LevelWarn,
+			want:  `{"err":"read: EOF"}`,
+		},
+		{
+			name: "custom-below-all",
+			modes: []errorsctx.LevelMode{
+				{Level: slog.LevelWarn, Mode: errorsctx.ErrorModeTree},
+			},
+			level: slog.LevelInfo,
+			want:  `{"err":"connect: connection refused"}`,
+		},
+	} {
+		for _, direct := range []bool{false, true} {
+			name := tt.name
+			var opts []errorsctx.HandlerOption
+			if direct {
+				name += "-direct"
+				opts = append(opts, errorsctx.WithDirectJSON())
+			}
+
+			t.Run(name, func(t *testing.T) {
+				var buf bytes.Buffer
+				inner := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+					Level: slog.LevelDebug,
+					ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+						if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+							return slog.Attr{}
+						}
+						return a
+					},
+				})
+				log := slog.New(errorsctx.NewSLogHandlerLeveled(inner, tt.modes...).WithOptions(opts...))
+
+				e := cmp.Or(tt.err, error(err))
+				log.Log(t.Context(), tt.level, "failed", slog.Any("err", e))
+				if got := strings.TrimSpace(buf.String()); got != tt.want {
+					t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+				}
+				if !json.Valid(buf.Bytes()) {
+					t.Errorf("invalid JSON output %s", buf.String())
+				}
+			})
+		}
+	}
+}
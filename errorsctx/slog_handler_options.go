@@ -0,0 +1,29 @@
+package errorsctx
+
+// HandlerOption tunes error handlers [SLogHandlerTree], [SLogHandlerFlat] and [SLogHandlerLeveled],
+// see [SLogHandlerLeveled.WithOptions] for the latter.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	directJSON bool
+}
+
+// WithDirectJSON makes handlers put error contexts as JSON encoded straight with
+// [errors.Error.AppendJSON], instead of slog groups. The output of [slog.JSONHandler] stays
+// the same, empty contexts are left out just like empty groups are, but it is produced several
+// times faster. Other handlers will not see the context as groups anymore, so this is for
+// JSON handlers only.
+func WithDirectJSON() HandlerOption {
+	return func(o *handlerOptions) {
+		o.directJSON = true
+	}
+}
+
+func newHandlerOptions(opts []HandlerOption) handlerOptions {
+	var res handlerOptions
+	for _, opt := range opts {
+		opt(&res)
+	}
+
+	return res
+}
@@ -3,19 +3,19 @@ package errorsctx
 import (
 	"context"
 	"log/slog"
-
-	"github.com/sirkon/errors"
 )
 
 // SLogHandlerTree handler for a tree view of an error context.
 type SLogHandlerTree struct {
 	handler slog.Handler
+	opts    handlerOptions
 }
 
 // NewSLogHandlerTree creates handler [SLogHandlerTree].
-func NewSLogHandlerTree(handler slog.Handler) *SLogHandlerTree {
+func NewSLogHandlerTree(handler slog.Handler, opts ...HandlerOption) *SLogHandlerTree {
 	return &SLogHandlerTree{
 		handler: handler,
+		opts:    newHandlerOptions(opts),
 	}
 }
 
@@ -26,47 +26,18 @@ func (h *SLogHandlerTree) Enabled(ctx context.Context, level slog.Level) bool {
 func (h *SLogHandlerTree) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &SLogHandlerTree{
 		handler: h.handler.WithAttrs(attrs),
+		opts:    h.opts,
 	}
 }
 
 func (h *SLogHandlerTree) WithGroup(name string) slog.Handler {
 	return &SLogHandlerTree{
 		handler: h.handler.WithGroup(name),
+		opts:    h.opts,
 	}
 }
 
 // Handle handles errors.
 func (h *SLogHandlerTree) Handle(ctx context.Context, r slog.Record) error {
-	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
-
-	r.Attrs(func(a slog.Attr) bool {
-		e, ok := a.Value.Any().(error)
-		if !ok {
-			newRecord.AddAttrs(a)
-			return true
-		}
-		err, ok := e.(*errors.Error)
-		if !ok {
-			err, ok = errors.AsType[*errors.Error](e)
-			if !ok {
-				newRecord.AddAttrs(a)
-				return true
-			}
-		}
-
-		if a.Key == "" || a.Key == "!BADKEY" {
-			a.Key = "err"
-		}
-
-		// Add error as err-key.@text and err-key.@context.
-		newRecord.AddAttrs(slog.GroupAttrs(
-			a.Key,
-			slog.String("@text", e.Error()),
-			slog.GroupAttrs("@context", errors.SLogTreeContext(err)...),
-		))
-		return true
-
-	})
-
-	return h.handler.Handle(ctx, newRecord)
+	return h.handler.Handle(ctx, recordWithErrors(r, ErrorModeTree, h.opts))
 }
@@ -0,0 +1,217 @@
+package errorsctx
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/internal/location"
+)
+
+// VModuleEnv is the environment variable [NewSLogHandlerVModule] takes initial rules from.
+const VModuleEnv = "ERRORS_VMODULE"
+
+// SLogHandlerVModule handler applies per package or per file levels to records, like
+// glog's -vmodule does. The calling site is found with [slog.Record.PC].
+//
+// Rules are comma separated pattern=level pairs, such as
+//
+//	storage/*=debug,http=warn,server.go=error
+//
+// Patterns ending with ".go" match files, the rest match packages. A pattern matches when
+// it matches, with [path.Match], the whole import path or file path or any trailing part
+// of it split by slashes: "http" matches "net/http" and "github.com/user/app/http", and
+// "storage/*" matches "github.com/user/app/storage/pg". The first matching rule wins,
+// records from sites not matched by any rule use the base level.
+//
+// Records passed the filter are given to the wrapped handler's Handle directly, its own
+// level is not consulted. This handler can be put in front of [SlogPrettyRenderer],
+// [SLogHandlerTree] or [SLogHandlerFlat] as well as behind them.
+type SLogHandlerVModule struct {
+	handler slog.Handler
+	state   *vmoduleState
+}
+
+// NewSLogHandlerVModule creates handler [SLogHandlerVModule] with the given base level,
+// nil level means [slog.LevelInfo]. Initial rules are taken from [VModuleEnv] environment
+// variable, an error is returned if they are invalid.
+func NewSLogHandlerVModule(handler slog.Handler, level slog.Leveler) (*SLogHandlerVModule, error) {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	res := &SLogHandlerVModule{
+		handler: handler,
+		state: &vmoduleState{
+			level: level,
+		},
+	}
+	res.state.rules.Store(&vmoduleRules{})
+	if err := res.Set(os.Getenv(VModuleEnv)); err != nil {
+		return nil, errors.Wrap(err, "parse $"+VModuleEnv)
+	}
+
+	return res, nil
+}
+
+// Set replaces rules at runtime. Rules are kept intact if the spec is invalid.
+// Handlers derived with WithAttrs and WithGroup share rules with this one.
+func (h *SLogHandlerVModule) Set(spec string) error {
+	rules, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	h.state.rules.Store(rules)
+	return nil
+}
+
+// Spec returns current rules.
+func (h *SLogHandlerVModule) Spec() string {
+	return h.state.rules.Load().spec
+}
+
+func (h *SLogHandlerVModule) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= min(h.state.level.Level(), h.state.rules.Load().min)
+}
+
+func (h *SLogHandlerVModule) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SLogHandlerVModule{
+		handler: h.handler.WithAttrs(attrs),
+		state:   h.state,
+	}
+}
+
+func (h *SLogHandlerVModule) WithGroup(name string) slog.Handler {
+	return &SLogHandlerVModule{
+		handler: h.handler.WithGroup(name),
+		state:   h.state,
+	}
+}
+
+// Handle passes the record to the wrapped handler if its level is enabled for the calling site.
+func (h *SLogHandlerVModule) Handle(ctx context.Context, r slog.Record) error {
+	level, ok := h.state.rules.Load().levelFor(r.PC)
+	if !ok {
+		level = h.state.level.Level()
+	}
+	if r.Level < level {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+type vmoduleState struct {
+	level slog.Leveler
+	rules atomic.Pointer[vmoduleRules]
+}
+
+type vmoduleRules struct {
+	spec  string
+	rules []vmoduleRule
+	// min is the lowest level of rules, it is compared with the base level in Enabled.
+	min slog.Level
+	// sites caches matched levels by program counters.
+	sites sync.Map
+}
+
+type vmoduleRule struct {
+	pattern string
+	file    bool
+	level   slog.Level
+}
+
+// vmoduleSite is a cached result of matching a calling site.
+type vmoduleSite struct {
+	level   slog.Level
+	matched bool
+}
+
+func parseVModule(spec string) (*vmoduleRules, error) {
+	res := &vmoduleRules{
+		spec: spec,
+		min:  slog.Level(1 << 30),
+	}
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pattern, levelText, ok := strings.Cut(item, "=")
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if !ok || pattern == "" {
+			return nil, errors.New("invalid rule, must be pattern=level").Str("rule", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrap(err, "invalid pattern").Str("rule", item)
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(levelText))); err != nil {
+			return nil, errors.Wrap(err, "invalid level").Str("rule", item)
+		}
+
+		res.rules = append(res.rules, vmoduleRule{
+			pattern: pattern,
+			file:    strings.HasSuffix(pattern, ".go"),
+			level:   level,
+		})
+		res.min = min(res.min, level)
+	}
+
+	return res, nil
+}
+
+// levelFor returns a level of the first rule matching the calling site.
+func (r *vmoduleRules) levelFor(pc uintptr) (slog.Level, bool) {
+	if len(r.rules) == 0 || pc == 0 {
+		return 0, false
+	}
+
+	if site, ok := r.sites.Load(pc); ok {
+		return site.(vmoduleSite).level, site.(vmoduleSite).matched
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := location.FunctionPackage(frame.Function)
+	var site vmoduleSite
+	for _, rule := range r.rules {
+		name := pkg
+		if rule.file {
+			name = frame.File
+		}
+
+		if matchPathSuffix(rule.pattern, name) {
+			site = vmoduleSite{level: rule.level, matched: true}
+			break
+		}
+	}
+	r.sites.Store(pc, site)
+
+	return site.level, site.matched
+}
+
+// matchPathSuffix checks if the pattern matches the name or any of its trailing parts split by slashes.
+func matchPathSuffix(pattern, name string) bool {
+	for name != "" {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+
+		_, rest, found := strings.Cut(name, "/")
+		if !found {
+			return false
+		}
+		name = rest
+	}
+
+	return false
+}
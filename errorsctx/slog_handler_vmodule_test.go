@@ -0,0 +1,93 @@
+package errorsctx_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
+)
+
+func TestSLogHandlerVModule(t *testing.T) {
+	t.Setenv(errorsctx.VModuleEnv, "errorsctx_test=debug")
+
+	var buf bytes.Buffer
+	// The level of the wrapped handler must not matter.
+	inner := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError})
+	handler, err := errorsctx.NewSLogHandlerVModule(errorsctx.NewSLogHandlerTree(inner), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(handler).With("component", "test")
+
+	log.Debug("debug enabled by env", slog.Any("err", errors.New("failure").Int("code", 1)))
+	if out := buf.String(); !strings.Contains(out, "msg=\"debug enabled by env\"") ||
+		!strings.Contains(out, `"err.@context.NEW: failure.code"=1`) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	for _, tt := range []struct {
+		spec  string
+		level slog.Level
+		want  bool
+	}{
+		{spec: "", level: slog.LevelDebug, want: false},
+		{spec: "", level: slog.LevelInfo, want: true},
+		{spec: "errors/*=warn", level: slog.LevelInfo, want: false},
+		{spec: "sirkon/errors/*=warn", level: slog.LevelWarn, want: true},
+		{spec: "storage/*=debug,errorsctx_test=error", level: slog.LevelWarn, want: false},
+		{spec: "slog_handler_vmodule_test.go=debug,errorsctx_test=error", level: slog.LevelDebug, want: true},
+		{spec: "errorsctx/*.go=error", level: slog.LevelWarn, want: false},
+		{spec: "other.go=debug", level: slog.LevelDebug, want: false},
+		{spec: " errorsctx_test = DEBUG+2 ", level: slog.LevelDebug, want: false},
+		{spec: " errorsctx_test = DEBUG+2 ", level: slog.LevelInfo, want: true},
+	} {
+		if err := handler.Set(tt.spec); err != nil {
+			t.Fatalf("set %q: %v", tt.spec, err)
+		}
+
+		buf.Reset()
+		log.Log(t.Context(), tt.level, "message")
+		if got := buf.Len() > 0; got != tt.want {
+			t.Errorf("spec %q level %s: got logged %t, want %t", tt.spec, tt.level, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"storage", "=debug", "storage=verbose", "[=debug"} {
+		if err := handler.Set(spec); err == nil {
+			t.Errorf("spec %q must be rejected", spec)
+		}
+	}
+	if got := handler.Spec(); got != " errorsctx_test = DEBUG+2 " {
+		t.Errorf("invalid spec must keep previous rules, got %q", got)
+	}
+
+	t.Setenv(errorsctx.VModuleEnv, "storage")
+	if _, err := errorsctx.NewSLogHandlerVModule(inner, nil); err == nil {
+		t.Error("invalid rules in the environment must be reported")
+	}
+}
+
+func TestSLogHandlerVModulePretty(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	handler, err := errorsctx.NewSLogHandlerVModule(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0), level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(handler)
+
+	log.Info("skipped")
+	if buf.Len() > 0 {
+		t.Errorf("info must be filtered out by base level, got %q", buf.String())
+	}
+
+	level.Set(slog.LevelInfo)
+	log.Info("passed")
+	if !strings.Contains(stripANSI(buf.String()), "INFO passed") {
+		t.Errorf("info must pass after base level change, got %q", buf.String())
+	}
+}
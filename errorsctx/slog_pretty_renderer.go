@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/sirkon/errors"
@@ -32,6 +34,9 @@ const (
 	KindErrorText
 	KindErrorNode
 	KindStackTrace
+	KindText    // Многострочный текст, выводится под ключом
+	KindSummary // Сводка по пропущенным из-за ограничений элементам
+	KindHexDump // Многострочный hex dump бинарных данных в стиле xxd
 )
 
 // TreeNode — единый элемент нашего сквозного промежуточного дерева
@@ -57,6 +62,27 @@ type SlogPrettyRenderer struct {
 	dst      io.Writer
 	color    *prettyWriterColorProfile
 	hexLimit int
+
+	linkTemplate string
+	snippetLines int
+	sources      *sourceCache
+	fullStacks   bool
+
+	keyFormatters  []keyFormatter
+	typeFormatters map[reflect.Type]ValueFormatter
+	heuristicsOff  []heuristicsRule
+
+	relativeTime   keyPatterns
+	humanDurations keyPatterns
+	byteSizes      keyPatterns
+	lastRecord     *atomic.Int64
+
+	limits RenderLimits
+
+	hexDumpMinSize int
+	hexDumpKeys    keyPatterns
+
+	trustedKeys keyPatterns
 }
 
 // NewSlogPrettyRenderer creates pretty output slog.Handler.
@@ -64,7 +90,14 @@ type SlogPrettyRenderer struct {
 //   - isDark applies respective color profile
 //   - hexLimit truncates binary data longer than the limit value. Here -1 disables this functionality and 0 is
 //     interpreted as 32.
-func NewSlogPrettyRenderer(dst io.Writer, opts *slog.HandlerOptions, isDark bool, hexLimit int) *SlogPrettyRenderer {
+//   - options tune optional features, see [PrettyRendererOption].
+func NewSlogPrettyRenderer(
+	dst io.Writer,
+	opts *slog.HandlerOptions,
+	isDark bool,
+	hexLimit int,
+	options ...PrettyRendererOption,
+) *SlogPrettyRenderer {
 	if opts == nil {
 		opts = &slog.HandlerOptions{}
 	}
@@ -77,12 +110,17 @@ func NewSlogPrettyRenderer(dst io.Writer, opts *slog.HandlerOptions, isDark bool
 	if hexLimit == 0 {
 		hexLimit = 32
 	}
-	return &SlogPrettyRenderer{
+	res := &SlogPrettyRenderer{
 		opts:     *opts,
 		dst:      dst,
 		color:    profile,
 		hexLimit: hexLimit,
 	}
+	for _, option := range options {
+		option(res)
+	}
+
+	return res
 }
 
 func (h *SlogPrettyRenderer) Enabled(_ context.Context, level slog.Level) bool {
@@ -108,6 +146,9 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 	buf = r.Time.AppendFormat(buf, "2006-01-02 15:04:05.000")
 	buf = append(buf, h.color.reset...)
 	buf = append(buf, ' ')
+	if h.lastRecord != nil {
+		buf = h.appendTimeDelta(buf, r.Time)
+	}
 
 	// 2. Отрисовка Уровня лога
 	switch r.Level {
@@ -127,14 +168,17 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 
 	// 3. Сообщение лога
 	buf = append(buf, h.color.bold...)
-	buf = append(buf, r.Message...)
+	buf = appendEscaped(buf, r.Message, false)
 	buf = append(buf, h.color.reset...)
 
 	forceTree := false
 	hasMultilineString := false
 	hasInternalJSON := false // <-- Добавить флаг
 
-	rawAttrs := make([]slog.Attr, 0, len(h.preAttrs)+r.NumAttrs())
+	// Атрибуты и IR записи живут в переиспользуемом билдере
+	b := h.newIRBuilder()
+	defer b.release()
+	rawAttrs := b.attrs[:0]
 
 	processRaw := func(a slog.Attr) {
 		val := a.Value.Resolve()
@@ -151,7 +195,7 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 				hasMultilineString = true
 			}
 			// Эвристика: Если внутри плоской строки прилетел JSON, требуем дерево
-			if len(valStr) > 1 && (valStr[0] == '{' || valStr[0] == '[') {
+			if len(valStr) > 1 && (valStr[0] == '{' || valStr[0] == '[') && h.heuristic(a.Key, HeuristicJSON) {
 				hasInternalJSON = true
 			}
 		}
@@ -165,13 +209,15 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 		processRaw(a)
 		return true
 	})
+	b.attrs = rawAttrs
 
 	// Сценарий 1: Мало контекста -> Компактный однострочный JSON
 	if !forceTree && !hasMultilineString && !hasInternalJSON && len(rawAttrs) <= 3 {
 		hasGroupsOrComplex := false
 		for _, a := range rawAttrs {
-			k := a.Value.Resolve().Kind()
-			if k == slog.KindGroup || k == slog.KindAny {
+			val := a.Value.Resolve()
+			k := val.Kind()
+			if k == slog.KindGroup || k == slog.KindAny || h.hasFormatter(a.Key, val) {
 				hasGroupsOrComplex = true
 				break
 			}
@@ -192,9 +238,10 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 					buf = append(buf, ", "...)
 					buf = append(buf, h.color.reset...)
 				}
+				trusted := h.trusted(a.Key)
 				buf = append(buf, h.color.key...)
 				buf = append(buf, '"')
-				buf = append(buf, a.Key...)
+				buf = appendText(buf, a.Key, trusted)
 				buf = append(buf, '"')
 				buf = append(buf, h.color.reset...)
 				buf = append(buf, h.color.stdots...)
@@ -203,9 +250,11 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 
 				buf = append(buf, h.color.ctx...)
 				val := a.Value.Resolve()
-				if val.Kind() == slog.KindString {
+				if human, _, ok := h.humanize(a.Key, val); ok {
+					buf = append(buf, human...)
+				} else if val.Kind() == slog.KindString {
 					buf = append(buf, '"')
-					buf = appendRawSlogValue(buf, val)
+					buf = appendText(buf, h.limitString(val.String()), trusted)
 					buf = append(buf, '"')
 				} else {
 					buf = appendRawSlogValue(buf, val)
@@ -220,26 +269,32 @@ func (h *SlogPrettyRenderer) Handle(_ context.Context, r slog.Record) error {
 	}
 
 	// Сценарий 2: Построение Единого Промежуточного Дерева (IR) для всего контекста
-	rootNodes := make([]*TreeNode, 0, len(rawAttrs))
 	for _, a := range rawAttrs {
-		rootNodes = append(rootNodes, h.buildIRTree(a.Key, a.Value))
+		b.roots = append(b.roots, b.buildIRTree(a.Key, a.Value, 0))
 	}
 
 	// Линейный рендеринг готового IR-графа
-	buf = h.renderIRTree(buf, rootNodes, []bool{}, false)
+	buf = h.renderIRTree(buf, b.roots, b.states, false, false)
 	return nil
 }
 
 // buildIRTree — Фабрика сквозного IR-дерева с интегрированными эвристиками
-func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
+func (h *irBuilder) buildIRTree(key string, val slog.Value, depth int) *TreeNode {
 	resolved := val.Resolve()
-	node := &TreeNode{Key: key}
+	if node, ok := h.formatValue(key, resolved); ok {
+		return node
+	}
+	// Узел берётся из арены только после ранних выходов, чтобы не занимать слоты зря.
+	node := TreeNode{Key: key}
 
 	// 1. Группы slog.Group
 	if resolved.Kind() == slog.KindGroup {
-		node.Kind = KindGroup
+		if h.depthExceeded(depth) {
+			return h.summaryNode(key, "{…}")
+		}
+		node := h.node(TreeNode{Key: key, Kind: KindGroup})
 		for _, subAttr := range resolved.Group() {
-			node.Children = append(node.Children, h.buildIRTree(subAttr.Key, subAttr.Value))
+			node.Children = append(node.Children, h.buildIRTree(subAttr.Key, subAttr.Value, depth+1))
 		}
 		return node
 	}
@@ -266,21 +321,22 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 				if len(ctxAttrs) == 0 {
 					node.Kind = KindErrorText
 					node.Value = e.Error()
-					return node
+					return h.node(node)
 				}
 
 				// Есть слои — строим дерево
 				node.Kind = KindErrorNode
-				node.Children = append(node.Children, &TreeNode{
+				node := h.node(node)
+				node.Children = append(node.Children, h.node(TreeNode{
 					Key:   "@text",
 					Value: e.Error(),
 					Kind:  KindErrorText,
-				})
+				}))
 
-				ctxNode := &TreeNode{Key: "@context", Kind: KindErrorNode}
+				ctxNode := h.node(TreeNode{Key: "@context", Kind: KindErrorNode})
 
 				for _, subAttr := range ctxAttrs {
-					child := h.buildIRTree(subAttr.Key, subAttr.Value)
+					child := h.buildIRTree(subAttr.Key, subAttr.Value, depth+2)
 
 					// Если это слой с пустым контекстом (например WRAP: wrap с пустым значением)
 					if child.Kind == KindGroup && len(child.Children) == 1 {
@@ -303,7 +359,7 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 				// Чужая ошибка (foreign error) — выводим как плоскую строку
 				node.Kind = KindErrorText
 				node.Value = e.Error()
-				return node
+				return h.node(node)
 			}
 		}
 	}
@@ -321,11 +377,19 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 		}
 	}
 
+	// Бинарные []byte сразу выводим как hex
+	if b, ok := resolved.Any().([]byte); ok && resolved.Kind() == slog.KindAny {
+		if !isPrintableText(b) && h.heuristic(key, HeuristicHex) {
+			return h.binaryNode(key, b)
+		}
+	}
+
 	// 2. Железобетонная эвристика: Распознаем стектрейс Go по структуре текста
-	if (key == "stacktrace" || key == "stack" || strings.Contains(rawStr, "goroutine ")) && strings.Contains(rawStr, "\n") {
+	isStackTrace := key == "stacktrace" || key == "stack" || strings.Contains(rawStr, "goroutine ")
+	if isStackTrace && strings.Contains(rawStr, "\n") && h.heuristic(key, HeuristicStackTrace) {
 		node.Kind = KindStackTrace
 		node.Value = rawStr
-		return node
+		return h.node(node)
 	}
 
 	// 3. Эвристика: Перехват ошибок пакета sirkon/errors
@@ -343,11 +407,12 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 					node.Key = "err"
 				}
 				node.Kind = KindGroup
-				node.Children = append(node.Children, &TreeNode{Key: "@text", Value: e.Error(), Kind: KindErrorText})
+				node := h.node(node)
+				node.Children = append(node.Children, h.node(TreeNode{Key: "@text", Value: e.Error(), Kind: KindErrorText}))
 
-				ctxNode := &TreeNode{Key: "@context", Kind: KindGroup}
+				ctxNode := h.node(TreeNode{Key: "@context", Kind: KindGroup})
 				for _, subAttr := range errors.SLogTreeContext(err) {
-					ctxNode.Children = append(ctxNode.Children, h.buildIRTree(subAttr.Key, subAttr.Value))
+					ctxNode.Children = append(ctxNode.Children, h.buildIRTree(subAttr.Key, subAttr.Value, depth+2))
 				}
 				node.Children = append(node.Children, ctxNode)
 				return node
@@ -355,36 +420,29 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 		}
 	}
 
-	// 4. Эвристика: Парсинг вложенных JSON / Map структур
-	var anyObj any
-	isJSON := false
+	if human, kind, ok := h.humanize(key, resolved); ok {
+		node.Kind = kind
+		node.Value = human
+		return h.node(node)
+	}
 
-	var jsonData []byte
-	if len(rawStr) > 1 && (rawStr[0] == '{' || rawStr[0] == '[') {
-		if json.Unmarshal([]byte(rawStr), &anyObj) == nil {
-			isJSON = true
-			jsonData = []byte(rawStr)
-		}
-	} else if resolved.Kind() == slog.KindAny {
-		if jsonBytes, err := json.Marshal(resolved.Any()); err == nil && len(jsonBytes) > 1 {
-			if jsonBytes[0] == '{' || jsonBytes[0] == '[' {
-				if json.Unmarshal(jsonBytes, &anyObj) == nil {
-					isJSON = true
-				}
-			}
-			jsonData = jsonBytes
+	// 4. Эвристика: Парсинг вложенного в строки JSON
+	if len(rawStr) > 1 && (rawStr[0] == '{' || rawStr[0] == '[') && h.heuristic(key, HeuristicJSON) {
+		if json.Valid([]byte(rawStr)) {
+			return h.buildIRTreeFromJSONBytes(key, []byte(rawStr), depth)
 		}
 	}
 
-	if isJSON {
-		return h.buildIRTreeFromJSONBytes(key, jsonData)
+	// Остальные Go значения (структуры, мапы, слайсы, указатели) обходим рефлексией
+	if resolved.Kind() == slog.KindAny && rawStr == "" && resolved.Any() != nil {
+		return h.buildReflectTree(key, reflect.ValueOf(resolved.Any()), depth, nil)
 	}
 
 	// 5. Дефолтная обработка примитивов верхнего уровня slog
-	if key == "@location" || strings.Contains(rawStr, ".go:") {
+	if key == "@location" || strings.Contains(rawStr, ".go:") && h.heuristic(key, HeuristicLocation) {
 		node.Kind = KindLocation
 		node.Value = rawStr
-		return node
+		return h.node(node)
 	}
 
 	switch resolved.Kind() {
@@ -396,30 +454,37 @@ func (h *SlogPrettyRenderer) buildIRTree(key string, val slog.Value) *TreeNode {
 		node.Kind = KindString
 	}
 
-	buf := make([]byte, 0, 64)
-	buf = appendRawSlogValue(buf, resolved)
-	node.Value = string(buf)
-	return node
+	if resolved.Kind() == slog.KindString {
+		node.Value = h.limitString(resolved.String())
+		return h.node(node)
+	}
+
+	h.scratch = appendRawSlogValue(h.scratch[:0], resolved)
+	node.Value = string(h.scratch)
+	if node.Kind == KindString {
+		node.Value = h.limitString(node.Value)
+	}
+	return h.node(node)
 }
 
-func (h *SlogPrettyRenderer) buildIRTreeFromJSONBytes(key string, data []byte) *TreeNode {
+func (h *irBuilder) buildIRTreeFromJSONBytes(key string, data []byte, depth int) *TreeNode {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
 
 	// Передаем управление рекурсивному токен-парсеру
-	return h.parseJSONToken(key, dec)
+	return h.parseJSONToken(key, dec, depth)
 }
 
-func (h *SlogPrettyRenderer) parseJSONToken(key string, dec *json.Decoder) *TreeNode {
+func (h *irBuilder) parseJSONToken(key string, dec *json.Decoder, depth int) *TreeNode {
 	t, err := dec.Token()
 	if err != nil {
-		return &TreeNode{Key: key, Kind: KindNull, Value: "null"}
+		return h.node(TreeNode{Key: key, Kind: KindNull, Value: "null"})
 	}
 
 	delim, ok := t.(json.Delim)
 	if !ok {
 		// Это примитив (string, number, bool, null)
-		node := &TreeNode{Key: key}
+		node := h.node(TreeNode{Key: key})
 		switch v := t.(type) {
 		case bool:
 			node.Kind = KindBool
@@ -444,40 +509,36 @@ func (h *SlogPrettyRenderer) parseJSONToken(key string, dec *json.Decoder) *Tree
 				}
 			}
 		case string:
+			if formatted, ok := h.formatValue(key, slog.StringValue(v)); ok {
+				return formatted
+			}
+
 			// Пытаемся раскрыть Base64
-			if decoded, ok := h.tryDecodeBase64(v); ok {
+			var decoded any
+			if h.heuristic(key, HeuristicBase64) {
+				decoded, _ = h.tryDecodeBase64(v)
+			}
+			if decoded != nil {
 				switch res := decoded.(type) {
 				case string:
 					// Успешно декодировали в Unicode текст
 					node.Kind = KindString
-					node.Value = res // Заменяем Base64 на чистый текст
+					node.Value = h.limitString(res) // Заменяем Base64 на чистый текст
 
 				case []byte:
-					// Это бинарные данные. Кодируем в красивую шестнадцатеричную строку
-					node.Kind = KindString
-					node.RawDisplay = true // Выводим без кавычек
-					node.IsHex = true
-
-					maxLen := len(res)
-					truncated := false
-					if maxLen > h.hexLimit && h.hexLimit > 0 {
-						maxLen = h.hexLimit
-						truncated = true
+					if !h.heuristic(key, HeuristicHex) {
+						// Hex отключён: оставляем исходную строку
+						node.Kind = KindString
+						node.Value = v
+						break
 					}
 
-					// Превращаем байты в hex-строку вида 0x7f07cea5...
-					hexStr := hex.EncodeToString(res[:maxLen])
-
-					if truncated {
-						node.Value = fmt.Sprintf("%s... (%d bytes)", hexStr, len(res))
-					} else {
-						node.Value = hexStr
-					}
+					return h.binaryNode(key, res)
 				}
 			} else {
 				// Обычная строка, оставляем как есть
 				node.Kind = KindString
-				node.Value = v
+				node.Value = h.limitString(v)
 			}
 		default:
 			node.Kind = KindNull
@@ -486,38 +547,106 @@ func (h *SlogPrettyRenderer) parseJSONToken(key string, dec *json.Decoder) *Tree
 		return node
 	}
 
-	node := &TreeNode{Key: key}
+	if h.depthExceeded(depth) {
+		skipJSONContainer(dec)
+		if delim == '[' {
+			return h.summaryNode(key, "[…]")
+		}
+		return h.summaryNode(key, "{…}")
+	}
+
+	node := h.node(TreeNode{Key: key})
 	switch delim {
 	case '{':
 		node.Kind = KindGroup
+		n := 0
 		for dec.More() {
 			// Читаем ключ объекта — json.Decoder гарантирует исходный порядок!
 			kToken, _ := dec.Token()
 			k := kToken.(string)
 
-			// Рекурсивно парсим значение для этого ключа
-			node.Children = append(node.Children, h.parseJSONToken(k, dec))
+			if h.limits.MaxMapEntries > 0 && n >= h.limits.MaxMapEntries {
+				skipJSONValue(dec)
+			} else {
+				// Рекурсивно парсим значение для этого ключа
+				node.Children = append(node.Children, h.parseJSONToken(k, dec, depth+1))
+			}
+			n++
 		}
 		dec.Token() // Закрываем '}'
+		if skipped := n - len(node.Children); skipped > 0 {
+			node.Children = append(node.Children, h.summaryNode("", moreItemsText(skipped, "entries")))
+		}
 	case '[':
 		node.Kind = KindArray
 		i := 0
 		for dec.More() {
-			node.Children = append(node.Children, h.parseJSONToken("["+strconv.Itoa(i)+"]", dec))
+			if h.limits.MaxArrayItems > 0 && i >= h.limits.MaxArrayItems {
+				skipJSONValue(dec)
+			} else {
+				node.Children = append(node.Children, h.parseJSONToken(indexKey(i), dec, depth+1))
+			}
 			i++
 		}
 		dec.Token() // Закрываем ']'
+		if skipped := i - len(node.Children); skipped > 0 {
+			node.Children = append(node.Children, h.summaryNode("", moreItemsText(skipped, "items")))
+		}
 	}
 	return node
 }
 
-func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states []bool, inErrorZone bool) []byte {
+// binaryNode строит узел для бинарных данных: однострочный hex или hex dump.
+func (h *irBuilder) binaryNode(key string, data []byte) *TreeNode {
+	if h.hexDump(key, data) {
+		return h.node(TreeNode{
+			Key:   key,
+			Value: string(data),
+			Kind:  KindHexDump,
+		})
+	}
+
+	// Это бинарные данные. Кодируем в красивую шестнадцатеричную строку
+	node := TreeNode{
+		Key:        key,
+		Kind:       KindString,
+		RawDisplay: true, // Выводим без кавычек
+		IsHex:      true,
+	}
+
+	maxLen := len(data)
+	truncated := false
+	if maxLen > h.hexLimit && h.hexLimit > 0 {
+		maxLen = h.hexLimit
+		truncated = true
+	}
+
+	// Превращаем байты в hex-строку вида 0x7f07cea5...
+	hexStr := hex.EncodeToString(data[:maxLen])
+
+	if truncated {
+		node.Value = fmt.Sprintf("%s... (%d bytes)", hexStr, len(data))
+	} else {
+		node.Value = hexStr
+	}
+	return h.node(node)
+}
+
+func (h *SlogPrettyRenderer) renderIRTree(
+	buf []byte,
+	nodes []*TreeNode,
+	states []bool,
+	inErrorZone bool,
+	trusted bool,
+) []byte {
 	count := len(nodes)
 	for i, node := range nodes {
 		isLast := i == count-1
+		// Доверенные ключи выводятся как есть вместе со всеми вложенными значениями
+		nodeTrusted := trusted || h.trusted(node.Key)
 
 		if node.Kind == KindStackTrace {
-			buf = h.appendFormattedStackTrace(buf, node.Key, node.Value, states, isLast)
+			buf = h.appendFormattedStackTrace(buf, node.Key, node.Value, states, isLast, nodeTrusted)
 			continue
 		}
 
@@ -546,16 +675,39 @@ func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states
 		case KindInlineArray:
 			// Печатаем как примитив, но строго без кавычек
 			buf = append(buf, h.color.ctx...) // используем цвет текста
-			buf = append(buf, node.Value...)
+			buf = appendText(buf, node.Value, nodeTrusted)
 		default:
 			buf = append(buf, h.color.key...) // Обычные пользовательские ключи
 		}
-		buf = append(buf, node.Key...)
+		buf = appendText(buf, node.Key, nodeTrusted)
 		buf = append(buf, h.color.reset...)
 
 		isGroupType := node.Kind == KindGroup || node.Kind == KindArray || node.Kind == KindErrorNode
-		if isGroupType {
-			buf = h.renderIRTree(buf, node.Children, append(states, isLast), inErrorZone)
+		if node.Kind == KindSummary {
+			if node.Key != "" {
+				buf = append(buf, h.color.stdots...)
+				buf = append(buf, ": "...)
+				buf = append(buf, h.color.reset...)
+			}
+			buf = append(buf, h.color.trace...)
+			buf = appendText(buf, node.Value, nodeTrusted)
+			buf = append(buf, h.color.reset...)
+		} else if isGroupType {
+			buf = h.renderIRTree(buf, node.Children, append(states, isLast), inErrorZone, nodeTrusted)
+		} else if node.Kind == KindHexDump {
+			buf = h.appendHexDump(buf, node.Value, append(states, isLast))
+		} else if node.Kind == KindText {
+			// Многострочный текст выводим построчно под ключом
+			for line := range strings.SplitSeq(node.Value, "\n") {
+				buf = h.appendStackLineIndent(buf, append(states, isLast))
+				buf = append(buf, h.color.ctx...)
+				if nodeTrusted {
+					buf = append(buf, line...)
+				} else {
+					buf = appendEscaped(buf, line, true)
+				}
+				buf = append(buf, h.color.reset...)
+			}
 		} else {
 			buf = append(buf, h.color.stdots...)
 			buf = append(buf, ": "...)
@@ -565,10 +717,14 @@ func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states
 			switch node.Kind {
 			case KindLocation:
 				buf = append(buf, h.color.loc...)
-				buf = append(buf, node.Value...)
+				buf = h.appendLocation(buf, node.Value)
+				if h.sources != nil {
+					buf = append(buf, h.color.reset...)
+					buf = h.appendSourceSnippet(buf, node.Value, append(states, isLast))
+				}
 			case KindErrorText:
 				buf = append(buf, h.color.error...) // Само тело ошибки горит красным
-				buf = append(buf, node.Value...)
+				buf = appendText(buf, node.Value, nodeTrusted)
 			case KindString:
 				if node.IsHex {
 					// 1. Печатаем приглушенный префикс "hex("
@@ -578,7 +734,7 @@ func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states
 
 					// 2. Печатаем само значение (его по-прежнему будет удобно выделять даблкликом!)
 					buf = append(buf, h.color.ctx...)
-					buf = append(buf, node.Value...)
+					buf = appendText(buf, node.Value, nodeTrusted)
 					buf = append(buf, h.color.reset...)
 
 					// 3. Печатаем приглушенную закрывающую скобку ")"
@@ -589,23 +745,23 @@ func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states
 					// Старая логика для обычных строк
 					buf = append(buf, h.color.ctx...)
 					if node.RawDisplay || strings.HasPrefix(node.Key, "[") {
-						buf = append(buf, node.Value...)
+						buf = appendText(buf, node.Value, nodeTrusted)
 					} else {
 						buf = append(buf, '"')
-						buf = append(buf, node.Value...)
+						buf = appendText(buf, node.Value, nodeTrusted)
 						buf = append(buf, '"')
 					}
 					buf = append(buf, h.color.reset...)
 				}
 			case KindNull:
 				buf = append(buf, h.color.trace...)
-				buf = append(buf, node.Value...)
+				buf = appendText(buf, node.Value, nodeTrusted)
 			case KindBool, KindNumber:
 				buf = append(buf, h.color.debug...)
-				buf = append(buf, node.Value...)
+				buf = appendText(buf, node.Value, nodeTrusted)
 			default:
 				buf = append(buf, h.color.ctx...)
-				buf = append(buf, node.Value...)
+				buf = appendText(buf, node.Value, nodeTrusted)
 			}
 			buf = append(buf, h.color.reset...)
 		}
@@ -613,7 +769,14 @@ func (h *SlogPrettyRenderer) renderIRTree(buf []byte, nodes []*TreeNode, states
 	return buf
 }
 
-func (h *SlogPrettyRenderer) appendFormattedStackTrace(buf []byte, key, stackStr string, states []bool, isCurrentLast bool) []byte {
+func (h *SlogPrettyRenderer) appendFormattedStackTrace(
+	buf []byte,
+	key string,
+	stackStr string,
+	states []bool,
+	isCurrentLast bool,
+	trusted bool,
+) []byte {
 	buf = append(buf, '\n')
 	buf = append(buf, h.color.link...)
 	for _, isParentLast := range states {
@@ -631,57 +794,33 @@ func (h *SlogPrettyRenderer) appendFormattedStackTrace(buf []byte, key, stackStr
 	buf = append(buf, h.color.reset...)
 
 	buf = append(buf, h.color.errkey...)
-	buf = append(buf, key...)
+	buf = appendText(buf, key, trusted)
 	buf = append(buf, h.color.reset...)
 	buf = append(buf, h.color.stdots...)
 	buf = append(buf, ": "...)
 	buf = append(buf, h.color.reset...)
 
 	fullStates := append(states, isCurrentLast)
-	lines := strings.Split(stackStr, "\n")
-
-	for i := 0; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-		if strings.HasPrefix(line, "goroutine ") {
+	if !trusted {
+		stackStr = escapeMultiline(stackStr)
+	}
+	tb, ok := parseTraceback(stackStr, mainModulePath())
+	if !ok {
+		// Неизвестный формат: выводим строки как есть.
+		for line := range strings.SplitSeq(stackStr, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
 			buf = h.appendStackLineIndent(buf, fullStates)
-			buf = append(buf, h.color.panic...)
-			buf = append(buf, ' ')
+			buf = append(buf, h.color.sttext...)
 			buf = append(buf, line...)
-			buf = append(buf, ' ')
 			buf = append(buf, h.color.reset...)
-			continue
 		}
-		if i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-			if strings.Contains(nextLine, ".go:") || strings.Contains(nextLine, "s:") {
-				funcName := line
-				locInfo := nextLine
-				if idx := strings.LastIndex(locInfo, " "); idx != -1 {
-					locInfo = locInfo[:idx]
-				}
-				buf = h.appendStackLineIndent(buf, fullStates)
-				buf = append(buf, h.color.key...)
-				buf = append(buf, funcName...)
-				buf = append(buf, h.color.reset...)
-				buf = append(buf, h.color.stdots...)
-				buf = append(buf, " -> "...)
-				buf = append(buf, h.color.reset...)
-				buf = append(buf, h.color.loc...)
-				buf = append(buf, locInfo...)
-				buf = append(buf, h.color.reset...)
-				i++
-				continue
-			}
-		}
-		buf = h.appendStackLineIndent(buf, fullStates)
-		buf = append(buf, h.color.sttext...)
-		buf = append(buf, line...)
-		buf = append(buf, h.color.reset...)
+		return buf
 	}
-	return buf
+
+	return h.appendTraceback(buf, tb, fullStates)
 }
 
 func (h *SlogPrettyRenderer) appendStackLineIndent(buf []byte, fullStates []bool) []byte {
@@ -720,25 +859,31 @@ func (h *SlogPrettyRenderer) tryDecodeBase64(s string) (any, bool) {
 		return nil, false
 	}
 
-	// Проверяем, является ли результат валидной Unicode строкой
-	if utf8.Valid(decoded) {
-		// Дополнительный фильтр: проверяем, что это печатаемые символы, а не бинарный мусор, случайно совпавший с UTF-8
-		isPrintable := true
-		for _, r := range string(decoded) {
-			if r < 32 && r != '\n' && r != '\r' && r != '\t' {
-				isPrintable = false
-				break
-			}
-		}
-		if isPrintable {
-			return string(decoded), true
-		}
+	// Проверяем, является ли результат валидной печатаемой Unicode строкой
+	if isPrintableText(decoded) {
+		return string(decoded), true
 	}
 
 	// Если не текст — возвращаем как бинарный слайс
 	return decoded, true
 }
 
+// isPrintableText проверяет, что это валидный UTF-8 из печатаемых символов, а не бинарный мусор,
+// случайно совпавший с UTF-8.
+func isPrintableText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+
+	for _, r := range string(data) {
+		if r < 32 && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+
+	return true
+}
+
 func appendRawSlogValue(buf []byte, v slog.Value) []byte {
 	switch v.Kind() {
 	case slog.KindString:
@@ -761,12 +906,9 @@ func appendRawSlogValue(buf []byte, v slog.Value) []byte {
 }
 
 func (h *SlogPrettyRenderer) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &SlogPrettyRenderer{
-		opts:     h.opts,
-		dst:      h.dst,
-		color:    h.color,
-		preAttrs: append(append([]slog.Attr{}, h.preAttrs...), attrs...),
-	}
+	res := *h
+	res.preAttrs = append(append([]slog.Attr{}, h.preAttrs...), attrs...)
+	return &res
 }
 
 func (h *SlogPrettyRenderer) WithGroup(name string) slog.Handler { return h }
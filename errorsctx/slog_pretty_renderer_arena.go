@@ -0,0 +1,112 @@
+package errorsctx
+
+import (
+	"log/slog"
+	"strconv"
+	"sync"
+)
+
+const (
+	irChunkSize = 64
+	// irMaxChunks limits the size of arenas kept for reuse, so a single huge record
+	// does not pin its memory forever.
+	irMaxChunks = 16
+)
+
+// irBuilder builds IR of a single record. Its nodes are taken from a pooled arena and are
+// reused by later records, so rendering of typical records does not allocate nodes at all.
+// Nodes must not outlive the record, [ValueFormatter] results are not affected by this.
+type irBuilder struct {
+	*SlogPrettyRenderer
+
+	chunks [][]TreeNode
+	chunk  int
+	used   int
+
+	attrs   []slog.Attr
+	roots   []*TreeNode
+	states  []bool
+	scratch []byte
+}
+
+var irBuilderPool = sync.Pool{
+	New: func() any {
+		return &irBuilder{
+			states:  make([]bool, 0, 16),
+			scratch: make([]byte, 0, 64),
+		}
+	},
+}
+
+// newIRBuilder takes a builder for the renderer from the pool.
+func (h *SlogPrettyRenderer) newIRBuilder() *irBuilder {
+	b := irBuilderPool.Get().(*irBuilder)
+	b.SlogPrettyRenderer = h
+	return b
+}
+
+// release returns the builder to the pool. Nodes are cleared to not keep values
+// of the record alive, but their children slices are kept to be reused.
+func (h *irBuilder) release() {
+	for i := range min(h.chunk+1, len(h.chunks)) {
+		chunk := h.chunks[i]
+		if i == h.chunk {
+			chunk = chunk[:h.used]
+		}
+		for j := range chunk {
+			children := chunk[j].Children
+			clear(children)
+			chunk[j] = TreeNode{Children: children[:0]}
+		}
+	}
+	if len(h.chunks) > irMaxChunks {
+		h.chunks = h.chunks[:irMaxChunks]
+	}
+	h.chunk = 0
+	h.used = 0
+
+	clear(h.attrs)
+	h.attrs = h.attrs[:0]
+	clear(h.roots)
+	h.roots = h.roots[:0]
+	h.states = h.states[:0]
+	h.SlogPrettyRenderer = nil
+	irBuilderPool.Put(h)
+}
+
+// node places the node into the arena.
+func (h *irBuilder) node(n TreeNode) *TreeNode {
+	if h.chunk < len(h.chunks) && h.used == len(h.chunks[h.chunk]) {
+		h.chunk++
+		h.used = 0
+	}
+	if h.chunk == len(h.chunks) {
+		h.chunks = append(h.chunks, make([]TreeNode, irChunkSize))
+	}
+
+	slot := &h.chunks[h.chunk][h.used]
+	h.used++
+	if n.Children == nil {
+		n.Children = slot.Children
+	}
+	*slot = n
+
+	return slot
+}
+
+// indexKey returns "[i]" key of an array item, keys of first items are not allocated.
+func indexKey(i int) string {
+	if i < len(indexKeys) {
+		return indexKeys[i]
+	}
+
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+var indexKeys = func() []string {
+	res := make([]string, DefaultRenderLimits.MaxArrayItems)
+	for i := range res {
+		res[i] = "[" + strconv.Itoa(i) + "]"
+	}
+	return res
+}()
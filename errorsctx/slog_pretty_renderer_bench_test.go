@@ -0,0 +1,141 @@
+package errorsctx_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"runtime/debug"
+	"testing"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
+)
+
+// legacyRenderer creates the renderer as it was before IR was pooled, it is set
+// with the legacyrender build tag only.
+var legacyRenderer func(w io.Writer, opts *slog.HandlerOptions) slog.Handler
+
+// BenchmarkSlogPrettyRenderer renders payloads of internal/example. Results of the renderer
+// as it was before IR was pooled are added with the legacyrender build tag:
+//
+//	go test -tags legacyrender -run - -bench SlogPrettyRenderer ./errorsctx
+func BenchmarkSlogPrettyRenderer(b *testing.B) {
+	err := errors.New("this is an error").
+		Bytes("bytes", []byte{1, 2, 3}).
+		Bytes("text-bytes", []byte("Hello World!"))
+	err = errors.Wrap(err, "check error").
+		Int("count", 333).
+		Bool("is-wrap-layer", true)
+	err = errors.Just(err).
+		F64("pi", math.Pi).
+		F64("e", math.E)
+	stack := string(debug.Stack())
+
+	var log *slog.Logger
+	for _, bb := range []struct {
+		name string
+		log  func()
+	}{
+		{
+			name: "foreign-error",
+			log: func() {
+				log.Error("pure foreign error", slog.Any("err", io.EOF))
+			},
+		},
+		{
+			name: "error-tree",
+			log: func() {
+				log.Error("log error with tree structured context", slog.Any("err", err))
+			},
+		},
+		{
+			name: "marked-foreign-error",
+			log: func() {
+				log.Error(
+					"log marked foreign error with tree beneath",
+					slog.Any("err", errors.Spec(fmt.Errorf("foreign wrap: %w", err), new(0))),
+				)
+			},
+		},
+		{
+			name: "compact",
+			log: func() {
+				log.Info("simple info with ctx2", slog.Int("count", 42), slog.String("key", "value"))
+			},
+		},
+		{
+			name: "tree",
+			log: func() {
+				log.Info(
+					"simple info with ctx4",
+					slog.Int("count", 42),
+					slog.String("key", "value"),
+					slog.String("key", "value"),
+					slog.String("key", "value"),
+				)
+			},
+		},
+		{
+			name: "stack",
+			log: func() {
+				log.Info("simple stack", slog.String("stack", stack))
+			},
+		},
+		{
+			name: "map",
+			log: func() {
+				log.Info(
+					"with internal json",
+					slog.Any("obj", map[string]any{
+						"foo": "bar",
+						"data": map[string]int{
+							"k":  1,
+							"k2": 2,
+						},
+					}),
+					errorsctx.ForceTree(),
+				)
+			},
+		},
+		{
+			name: "text-json",
+			log: func() {
+				log.Info("with internal text json tree", slog.String("obj", `{"foo": "bar", "data": [1, 2, 3]}`))
+			},
+		},
+	} {
+		variants := []struct {
+			name    string
+			handler func() slog.Handler
+		}{
+			{
+				name: "pooled",
+				handler: func() slog.Handler {
+					return errorsctx.NewSlogPrettyRenderer(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}, true, -1)
+				},
+			},
+		}
+		if legacyRenderer != nil {
+			variants = append(variants, struct {
+				name    string
+				handler func() slog.Handler
+			}{
+				name: "legacy",
+				handler: func() slog.Handler {
+					return legacyRenderer(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})
+				},
+			})
+		}
+
+		for _, variant := range variants {
+			b.Run(bb.name+"/"+variant.name, func(b *testing.B) {
+				log = slog.New(variant.handler())
+				b.ReportAllocs()
+				for b.Loop() {
+					bb.log()
+				}
+			})
+		}
+	}
+}
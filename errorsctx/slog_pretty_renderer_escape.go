@@ -0,0 +1,106 @@
+package errorsctx
+
+import (
+	"unicode/utf8"
+)
+
+// WithTrustedKeys disables escaping of control characters for attributes whose keys match
+// any of the given [path.Match] patterns, all attributes are trusted when none are given.
+//
+// By default, the renderer escapes control characters, ANSI sequences, invalid UTF-8 and
+// bidirectional text overrides in keys, values, messages and stack traces, so a user
+// controlled string cannot rewrite the terminal or fake log lines. Use this option only
+// for values that are known to be safe, such as pre-colorized output of trusted tools.
+func WithTrustedKeys(patterns ...string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.trustedKeys = newKeyPatterns(patterns)
+	}
+}
+
+// trusted reports whether attributes with the given key are rendered verbatim.
+func (h *SlogPrettyRenderer) trusted(key string) bool {
+	return h.trustedKeys.match(key)
+}
+
+// appendText appends s escaping it unless it is trusted.
+func appendText(buf []byte, s string, trusted bool) []byte {
+	if trusted {
+		return append(buf, s...)
+	}
+
+	return appendEscaped(buf, s, false)
+}
+
+// appendEscaped appends s with unsafe characters replaced by Go-like escape sequences:
+// \n, \r and \t for respective characters, \xNN for other C0 controls, DEL and bytes of
+// invalid UTF-8, \uNNNN for C1 controls, line separators and bidirectional overrides.
+// Newlines, CRLF line endings and tabs are kept as is in the multiline mode.
+func appendEscaped(buf []byte, s string, multiline bool) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c < 0x7f || multiline && c == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+			i++
+			continue
+		}
+
+		r, size := rune(c), 1
+		if c >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(s[i:])
+		}
+		if !needsEscape(r, size, multiline) {
+			i += size
+			continue
+		}
+
+		buf = append(buf, s[start:i]...)
+		switch {
+		case r == '\n':
+			buf = append(buf, `\n`...)
+		case r == '\r':
+			buf = append(buf, `\r`...)
+		case r == '\t':
+			buf = append(buf, `\t`...)
+		case r < utf8.RuneSelf || r == utf8.RuneError && size == 1:
+			buf = append(buf, `\x`...)
+			buf = append(buf, hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			buf = append(buf, `\u`...)
+			buf = append(buf, hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+		}
+		i += size
+		start = i
+	}
+
+	return append(buf, s[start:]...)
+}
+
+func needsEscape(r rune, size int, multiline bool) bool {
+	switch {
+	case r == utf8.RuneError && size == 1:
+		return true
+	case r == '\n' || r == '\t':
+		return !multiline
+	case r < 0x20 || r == 0x7f:
+		return true
+	case r >= 0x80 && r <= 0x9f:
+		return true
+	case r == 0x2028 || r == 0x2029:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+
+	return false
+}
+
+// escapeMultiline escapes s keeping its newlines and tabs.
+func escapeMultiline(s string) string {
+	for i := range len(s) {
+		if c := s[i]; c < 0x20 && c != '\n' && c != '\t' || c >= 0x7f {
+			return string(appendEscaped(make([]byte, 0, len(s)+16), s, true))
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,154 @@
+package errorsctx
+
+import (
+	"log/slog"
+	"path"
+	"reflect"
+	"slices"
+)
+
+// ValueFormatter renders attribute values into [TreeNode] subtrees.
+type ValueFormatter interface {
+	// FormatValue returns false if it cannot handle the value, the renderer
+	// falls back to other formatters and its own rendering then.
+	FormatValue(key string, value slog.Value) (*TreeNode, bool)
+}
+
+// ValueFormatterFunc is a functional [ValueFormatter].
+type ValueFormatterFunc func(key string, value slog.Value) (*TreeNode, bool)
+
+// FormatValue to implement [ValueFormatter].
+func (f ValueFormatterFunc) FormatValue(key string, value slog.Value) (*TreeNode, bool) {
+	return f(key, value)
+}
+
+// Heuristic names built-in value recognition of the [SlogPrettyRenderer].
+type Heuristic int
+
+const (
+	// HeuristicJSON renders strings looking like JSON objects and arrays as trees.
+	HeuristicJSON Heuristic = iota + 1
+	// HeuristicBase64 decodes strings looking like base64 encoded data.
+	HeuristicBase64
+	// HeuristicHex renders binary data as hex.
+	HeuristicHex
+	// HeuristicStackTrace renders multiline strings mentioning goroutines as stack traces.
+	HeuristicStackTrace
+	// HeuristicLocation renders strings containing ".go:" as locations.
+	HeuristicLocation
+)
+
+// WithKeyFormatter registers formatter for keys matching the pattern. The pattern
+// syntax is the one of [path.Match], so "query", "*_sql" and "*" are all valid.
+// Formatters are tried in the order of registration and before the type ones.
+//
+// Key formatters also apply to string values of JSON objects met in attributes.
+func WithKeyFormatter(pattern string, formatter ValueFormatter) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.keyFormatters = append(h.keyFormatters, keyFormatter{
+			pattern:   pattern,
+			formatter: formatter,
+		})
+	}
+}
+
+// WithTypeFormatter registers formatter for values of the type T. Basic types are
+// matched by their slog representation: string, int64, uint64, float64, bool,
+// [time.Time] and [time.Duration].
+func WithTypeFormatter[T any](formatter ValueFormatter) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		if h.typeFormatters == nil {
+			h.typeFormatters = map[reflect.Type]ValueFormatter{}
+		}
+		h.typeFormatters[reflect.TypeFor[T]()] = formatter
+	}
+}
+
+// WithoutHeuristics disables given built-in heuristics for keys matching the pattern.
+// All of them are disabled if none are given. The pattern syntax is the one of [path.Match].
+func WithoutHeuristics(pattern string, heuristics ...Heuristic) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.heuristicsOff = append(h.heuristicsOff, heuristicsRule{
+			pattern:    pattern,
+			heuristics: heuristics,
+		})
+	}
+}
+
+type keyFormatter struct {
+	pattern   string
+	formatter ValueFormatter
+}
+
+type heuristicsRule struct {
+	pattern    string
+	heuristics []Heuristic
+}
+
+// formatValue applies registered formatters to the value.
+func (h *SlogPrettyRenderer) formatValue(key string, value slog.Value) (*TreeNode, bool) {
+	for _, f := range h.keyFormatters {
+		if ok, _ := path.Match(f.pattern, key); !ok {
+			continue
+		}
+
+		if node, ok := f.formatter.FormatValue(key, value); ok {
+			return keyedNode(node, key), true
+		}
+	}
+
+	if len(h.typeFormatters) == 0 || value.Kind() == slog.KindGroup || value.Kind() == slog.KindLogValuer {
+		return nil, false
+	}
+
+	f, ok := h.typeFormatters[reflect.TypeOf(value.Any())]
+	if !ok {
+		return nil, false
+	}
+
+	node, ok := f.FormatValue(key, value)
+	if !ok {
+		return nil, false
+	}
+
+	return keyedNode(node, key), true
+}
+
+// hasFormatter checks if some of registered formatters may apply to the value.
+func (h *SlogPrettyRenderer) hasFormatter(key string, value slog.Value) bool {
+	for _, f := range h.keyFormatters {
+		if ok, _ := path.Match(f.pattern, key); ok {
+			return true
+		}
+	}
+
+	if len(h.typeFormatters) == 0 || value.Kind() == slog.KindGroup || value.Kind() == slog.KindLogValuer {
+		return false
+	}
+
+	_, ok := h.typeFormatters[reflect.TypeOf(value.Any())]
+	return ok
+}
+
+// heuristic checks if the heuristic is enabled for the key.
+func (h *SlogPrettyRenderer) heuristic(key string, heuristic Heuristic) bool {
+	for _, rule := range h.heuristicsOff {
+		if ok, _ := path.Match(rule.pattern, key); !ok {
+			continue
+		}
+
+		if len(rule.heuristics) == 0 || slices.Contains(rule.heuristics, heuristic) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func keyedNode(node *TreeNode, key string) *TreeNode {
+	if node.Key == "" {
+		node.Key = key
+	}
+
+	return node
+}
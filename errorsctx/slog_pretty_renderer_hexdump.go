@@ -0,0 +1,99 @@
+package errorsctx
+
+import (
+	"strconv"
+)
+
+const hexDumpRowSize = 16
+
+// WithHexDump renders binary values as multiline xxd-style hex dumps with offsets and
+// an ASCII column instead of a single truncated hex line. Dumps are used for keys matching
+// any of the patterns and, if minSize is positive, for values of at least minSize bytes.
+// The pattern syntax is the one of [path.Match].
+//
+// The number of dumped bytes is bounded by [RenderLimits.MaxStringLen] if it is set.
+func WithHexDump(minSize int, patterns ...string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.hexDumpMinSize = minSize
+		h.hexDumpKeys = append(keyPatterns{}, patterns...)
+	}
+}
+
+func (h *SlogPrettyRenderer) hexDump(key string, data []byte) bool {
+	if h.hexDumpMinSize > 0 && len(data) >= h.hexDumpMinSize {
+		return true
+	}
+
+	return h.hexDumpKeys.match(key)
+}
+
+// appendHexDump renders data as rows like
+//
+//	00000000: 4865 6c6c 6f20 576f 726c 6421 0a00 0102  Hello World!....
+func (h *SlogPrettyRenderer) appendHexDump(buf []byte, data string, fullStates []bool) []byte {
+	buf = append(buf, h.color.stdots...)
+	buf = append(buf, ": "...)
+	buf = append(buf, h.color.reset...)
+	buf = append(buf, h.color.trace...)
+	buf = strconv.AppendInt(buf, int64(len(data)), 10)
+	buf = append(buf, " bytes"...)
+	buf = append(buf, h.color.reset...)
+
+	size := len(data)
+	if h.limits.MaxStringLen > 0 && size > h.limits.MaxStringLen {
+		size = h.limits.MaxStringLen
+	}
+
+	for offset := 0; offset < size; offset += hexDumpRowSize {
+		row := data[offset:min(offset+hexDumpRowSize, size)]
+
+		buf = h.appendStackLineIndent(buf, fullStates)
+		buf = append(buf, h.color.loc...)
+		buf = appendHexDumpOffset(buf, offset)
+		buf = append(buf, ':')
+		buf = append(buf, h.color.reset...)
+
+		buf = append(buf, h.color.ctx...)
+		for i := range hexDumpRowSize {
+			if i%2 == 0 {
+				buf = append(buf, ' ')
+			}
+			if i < len(row) {
+				buf = append(buf, hexDigits[row[i]>>4], hexDigits[row[i]&0x0f])
+			} else {
+				buf = append(buf, "  "...)
+			}
+		}
+		buf = append(buf, h.color.reset...)
+
+		buf = append(buf, "  "...)
+		buf = append(buf, h.color.sttext...)
+		for i := range len(row) {
+			if row[i] >= 0x20 && row[i] < 0x7f {
+				buf = append(buf, row[i])
+			} else {
+				buf = append(buf, '.')
+			}
+		}
+		buf = append(buf, h.color.reset...)
+	}
+
+	if size < len(data) {
+		buf = h.appendStackLineIndent(buf, fullStates)
+		buf = append(buf, h.color.trace...)
+		buf = append(buf, moreItemsText(len(data)-size, "bytes")...)
+		buf = append(buf, h.color.reset...)
+	}
+
+	return buf
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendHexDumpOffset(buf []byte, offset int) []byte {
+	for shift := 28; shift >= 0; shift -= 4 {
+		buf = append(buf, hexDigits[(offset>>shift)&0x0f])
+	}
+
+	return buf
+}
@@ -0,0 +1,225 @@
+package errorsctx
+
+import (
+	"log/slog"
+	"math"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ByteSize marks a value as a size in bytes. The pretty renderer shows it with binary
+// units like 1.5 MiB, other handlers see it as a plain number.
+type ByteSize int64
+
+// String to implement [fmt.Stringer].
+func (s ByteSize) String() string {
+	return string(appendByteSize(nil, int64(s)))
+}
+
+// WithRelativeTime renders time values of matching keys relative to the current time,
+// like "3.2s ago". Values of all keys are rendered this way if no patterns are given.
+// The pattern syntax is the one of [path.Match].
+func WithRelativeTime(patterns ...string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.relativeTime = newKeyPatterns(patterns)
+	}
+}
+
+// WithHumanDurations renders durations of matching keys rounded to a few significant digits,
+// like 1.23s instead of 1.234567891s. Values of all keys are rendered this way if no patterns
+// are given. The pattern syntax is the one of [path.Match].
+func WithHumanDurations(patterns ...string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.humanDurations = newKeyPatterns(patterns)
+	}
+}
+
+// WithByteSizes renders integer values of matching keys as sizes with binary units.
+// The pattern syntax is the one of [path.Match]. Values of the [ByteSize] type are
+// always rendered this way.
+func WithByteSizes(patterns ...string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.byteSizes = newKeyPatterns(patterns)
+	}
+}
+
+// WithTimeDelta adds the time passed since the previous record into the header of the record.
+// Handlers derived with WithAttrs and WithGroup share the previous record time.
+func WithTimeDelta() PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.lastRecord = new(atomic.Int64)
+	}
+}
+
+// keyPatterns is a list of [path.Match] patterns. Nil list matches nothing.
+type keyPatterns []string
+
+func newKeyPatterns(patterns []string) keyPatterns {
+	if len(patterns) == 0 {
+		return keyPatterns{"*"}
+	}
+
+	return append(keyPatterns{}, patterns...)
+}
+
+func (p keyPatterns) match(key string) bool {
+	for _, pattern := range p {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// humanize renders values in a human friendly way where it is enabled.
+func (h *SlogPrettyRenderer) humanize(key string, val slog.Value) (string, NodeKind, bool) {
+	switch val.Kind() {
+	case slog.KindTime:
+		if !h.relativeTime.match(key) {
+			return "", 0, false
+		}
+
+		d := time.Since(val.Time())
+		if d < 0 {
+			return "in " + string(appendHumanDuration(nil, -d)), KindNumber, true
+		}
+		return string(appendHumanDuration(nil, d)) + " ago", KindNumber, true
+
+	case slog.KindDuration:
+		if !h.humanDurations.match(key) {
+			return "", 0, false
+		}
+
+		return string(appendHumanDuration(nil, val.Duration())), KindNumber, true
+
+	case slog.KindInt64:
+		if !h.byteSizes.match(key) {
+			return "", 0, false
+		}
+
+		return string(appendByteSize(nil, val.Int64())), KindNumber, true
+
+	case slog.KindUint64:
+		if !h.byteSizes.match(key) || val.Uint64() > 1<<63-1 {
+			return "", 0, false
+		}
+
+		return string(appendByteSize(nil, int64(val.Uint64()))), KindNumber, true
+
+	case slog.KindAny:
+		size, ok := val.Any().(ByteSize)
+		if !ok {
+			return "", 0, false
+		}
+
+		return size.String(), KindNumber, true
+
+	default:
+		return "", 0, false
+	}
+}
+
+// appendTimeDelta appends the time passed since the previous record.
+func (h *SlogPrettyRenderer) appendTimeDelta(buf []byte, t time.Time) []byte {
+	prev := h.lastRecord.Swap(t.UnixNano())
+	if prev == 0 {
+		return buf
+	}
+
+	d := t.Sub(time.Unix(0, prev))
+	buf = append(buf, h.color.trace...)
+	if d < 0 {
+		buf = append(buf, '-')
+		d = -d
+	} else {
+		buf = append(buf, '+')
+	}
+	buf = appendHumanDuration(buf, d)
+	buf = append(buf, h.color.reset...)
+	return append(buf, ' ')
+}
+
+// appendHumanDuration appends duration rounded to three significant digits for
+// short durations and to seconds and minutes for long ones.
+func appendHumanDuration(buf []byte, d time.Duration) []byte {
+	if d < 0 {
+		buf = append(buf, '-')
+		// -math.MinInt64 переполняется, наносекунда погоды не делает.
+		d = -max(d, -math.MaxInt64)
+	}
+
+	switch {
+	case d < time.Microsecond:
+		return append(strconv.AppendInt(buf, int64(d), 10), "ns"...)
+	case d < time.Millisecond:
+		return appendScaled(buf, float64(d)/float64(time.Microsecond), "µs")
+	case d < time.Second:
+		return appendScaled(buf, float64(d)/float64(time.Millisecond), "ms")
+	case d < time.Minute:
+		return appendScaled(buf, d.Seconds(), "s")
+	case d < time.Hour:
+		return append(buf, d.Round(time.Second).String()...)
+	case d < 24*time.Hour:
+		return append(buf, d.Round(time.Minute).String()...)
+	default:
+		days := int64(d / (24 * time.Hour))
+		buf = strconv.AppendInt(buf, days, 10)
+		buf = append(buf, 'd')
+		hours := int64((d % (24 * time.Hour)).Round(time.Hour) / time.Hour)
+		if hours > 0 {
+			buf = strconv.AppendInt(buf, hours, 10)
+			buf = append(buf, 'h')
+		}
+		return buf
+	}
+}
+
+var byteSizeUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+func appendByteSize(buf []byte, size int64) []byte {
+	if size < 0 {
+		buf = append(buf, '-')
+		if size == -1<<63 {
+			return append(buf, "8 EiB"...)
+		}
+		size = -size
+	}
+	if size < 1024 {
+		return append(strconv.AppendInt(buf, size, 10), " B"...)
+	}
+
+	value := float64(size)
+	unit := 0
+	for value /= 1024; value >= 1024 && unit < len(byteSizeUnits)-1; value /= 1024 {
+		unit++
+	}
+
+	return appendScaled(buf, value, " "+byteSizeUnits[unit])
+}
+
+// appendScaled appends value with three significant digits and a unit.
+func appendScaled(buf []byte, value float64, unit string) []byte {
+	prec := 2
+	switch {
+	case value >= 100:
+		prec = 0
+	case value >= 10:
+		prec = 1
+	}
+
+	buf = strconv.AppendFloat(buf, value, 'f', prec, 64)
+	if prec > 0 {
+		// Убираем незначащие нули: 1.50 → 1.5, 2.00 → 2
+		for buf[len(buf)-1] == '0' {
+			buf = buf[:len(buf)-1]
+		}
+		if buf[len(buf)-1] == '.' {
+			buf = buf[:len(buf)-1]
+		}
+	}
+
+	return append(buf, unit...)
+}
@@ -0,0 +1,16 @@
+//go:build legacyrender
+
+package errorsctx_test
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/sirkon/errors/errorsctx/internal/legacyrender"
+)
+
+func init() {
+	legacyRenderer = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+		return legacyrender.NewSlogPrettyRenderer(w, opts, true, -1)
+	}
+}
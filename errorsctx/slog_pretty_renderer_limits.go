@@ -0,0 +1,98 @@
+package errorsctx
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+// RenderLimits bounds the amount of rendered data for large values. Zero fields mean no limit,
+// except for the depth, which is always bounded by [SafeMaxDepth] to survive deeply nested
+// and cyclic values.
+type RenderLimits struct {
+	// MaxArrayItems is the maximum number of shown array and slice items.
+	MaxArrayItems int
+	// MaxMapEntries is the maximum number of shown map and object entries.
+	MaxMapEntries int
+	// MaxDepth is the maximum nesting depth of shown values, deeper ones are shown as {…} and […].
+	MaxDepth int
+	// MaxStringLen is the maximum length of shown strings in bytes.
+	MaxStringLen int
+}
+
+// DefaultRenderLimits are limits fitting a terminal reasonably well.
+var DefaultRenderLimits = RenderLimits{
+	MaxArrayItems: 32,
+	MaxMapEntries: 64,
+	MaxDepth:      8,
+	MaxStringLen:  1024,
+}
+
+// WithLimits sets limits for the size of rendered values. Values are not limited by default,
+// except for the nesting depth bounded by [SafeMaxDepth].
+// Omitted items are summarized with "… N more items" nodes.
+func WithLimits(limits RenderLimits) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.limits = limits
+	}
+}
+
+// SafeMaxDepth is the nesting depth rendered values are bounded by when [RenderLimits] has no depth limit.
+const SafeMaxDepth = 64
+
+func (h *SlogPrettyRenderer) depthExceeded(depth int) bool {
+	if h.limits.MaxDepth > 0 {
+		return depth >= h.limits.MaxDepth
+	}
+
+	return depth >= SafeMaxDepth
+}
+
+// limitString truncates the string to the limit keeping UTF-8 sequences intact.
+func (h *SlogPrettyRenderer) limitString(s string) string {
+	if h.limits.MaxStringLen <= 0 || len(s) <= h.limits.MaxStringLen {
+		return s
+	}
+
+	cut := h.limits.MaxStringLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut] + "… (" + strconv.Itoa(len(s)) + " bytes)"
+}
+
+func (h *irBuilder) summaryNode(key, text string) *TreeNode {
+	return h.node(TreeNode{
+		Key:   key,
+		Value: text,
+		Kind:  KindSummary,
+	})
+}
+
+func moreItemsText(n int, what string) string {
+	return "… " + strconv.Itoa(n) + " more " + what
+}
+
+// skipJSONValue skips the next value of the decoder.
+func skipJSONValue(dec *json.Decoder) {
+	var raw json.RawMessage
+	_ = dec.Decode(&raw)
+}
+
+// skipJSONContainer skips the rest of the object or array which opening delimiter was already read.
+func skipJSONContainer(dec *json.Decoder) {
+	for depth := 1; depth > 0; {
+		t, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package errorsctx
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/internal/location"
+)
+
+// Location link templates for popular tools. {path} is replaced with the file path and {line} with the line number.
+const (
+	LocationLinkVSCode = "vscode://file{path}:{line}"
+	LocationLinkIDEA   = "idea://open?file={path}&line={line}"
+	LocationLinkFile   = "file://{path}"
+)
+
+// WithLocationLinks makes the renderer emit locations as OSC 8 terminal hyperlinks.
+// The template is an URL where {path} and {line} are substituted with respective
+// parts of a location, see LocationLink* constants for examples.
+//
+// Terminals not supporting OSC 8 ignore these sequences and show the plain location.
+func WithLocationLinks(template string) PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.linkTemplate = template
+	}
+}
+
+// appendLocation appends file:line location, wrapped into a hyperlink if it is enabled.
+// Links always point to original files even if locations are trimmed with [errors.TrimLocations].
+func (h *SlogPrettyRenderer) appendLocation(buf []byte, loc string) []byte {
+	if h.linkTemplate == "" {
+		return appendEscaped(buf, loc, false)
+	}
+
+	path, line, ok := location.Split(errors.OriginalLocation(loc))
+	if !ok {
+		return appendEscaped(buf, loc, false)
+	}
+
+	buf = append(buf, "\x1b]8;;"...)
+	buf = appendLocationURL(buf, h.linkTemplate, path, line)
+	buf = append(buf, "\x1b\\"...)
+	buf = appendEscaped(buf, loc, false)
+	buf = append(buf, "\x1b]8;;\x1b\\"...)
+	return buf
+}
+
+func appendLocationURL(buf []byte, template, path, line string) []byte {
+	// Escaping keeps the URL free of control characters and separators that would break the sequence.
+	path = (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+
+	for template != "" {
+		idx := strings.IndexByte(template, '{')
+		if idx < 0 {
+			break
+		}
+
+		buf = append(buf, template[:idx]...)
+		template = template[idx:]
+		switch {
+		case strings.HasPrefix(template, "{path}"):
+			buf = append(buf, path...)
+			template = template[len("{path}"):]
+		case strings.HasPrefix(template, "{line}"):
+			buf = append(buf, line...)
+			template = template[len("{line}"):]
+		default:
+			buf = append(buf, '{')
+			template = template[1:]
+		}
+	}
+
+	return append(buf, template...)
+}
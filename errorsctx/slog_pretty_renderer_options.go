@@ -0,0 +1,4 @@
+package errorsctx
+
+// PrettyRendererOption tunes optional features of [SlogPrettyRenderer].
+type PrettyRendererOption func(h *SlogPrettyRenderer)
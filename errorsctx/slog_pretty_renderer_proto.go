@@ -0,0 +1,220 @@
+package errorsctx
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// buildProtoTree renders protobuf message field by field. Only populated fields are shown,
+// in the order of their declaration. Well known types are rendered as their Go counterparts.
+func (h *irBuilder) buildProtoTree(key string, m protoreflect.Message, depth int) *TreeNode {
+	if !m.IsValid() {
+		return h.node(TreeNode{Key: key, Kind: KindNull, Value: "null"})
+	}
+	if node, ok := h.buildProtoWellKnown(key, m, depth); ok {
+		return node
+	}
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "{…}")
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindGroup})
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+
+		if h.limits.MaxMapEntries > 0 && len(node.Children) >= h.limits.MaxMapEntries {
+			node.Children = append(node.Children, h.summaryNode("", "… more fields"))
+			break
+		}
+		node.Children = append(node.Children, h.buildProtoField(string(fd.Name()), fd, m.Get(fd), depth+1))
+	}
+
+	return node
+}
+
+func (h *irBuilder) buildProtoField(
+	key string,
+	fd protoreflect.FieldDescriptor,
+	v protoreflect.Value,
+	depth int,
+) *TreeNode {
+	switch {
+	case fd.IsList():
+		return h.buildProtoList(key, fd, v.List(), depth)
+	case fd.IsMap():
+		return h.buildProtoMap(key, fd, v.Map(), depth)
+	default:
+		return h.buildProtoValue(key, fd, v, depth)
+	}
+}
+
+func (h *irBuilder) buildProtoList(
+	key string,
+	fd protoreflect.FieldDescriptor,
+	list protoreflect.List,
+	depth int,
+) *TreeNode {
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "[…]")
+	}
+
+	shown := list.Len()
+	if h.limits.MaxArrayItems > 0 && shown > h.limits.MaxArrayItems {
+		shown = h.limits.MaxArrayItems
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindArray})
+	for i := range shown {
+		node.Children = append(node.Children, h.buildProtoValue(indexKey(i), fd, list.Get(i), depth+1))
+	}
+	if shown < list.Len() {
+		node.Children = append(node.Children, h.summaryNode("", moreItemsText(list.Len()-shown, "items")))
+	}
+
+	return node
+}
+
+func (h *irBuilder) buildProtoMap(
+	key string,
+	fd protoreflect.FieldDescriptor,
+	m protoreflect.Map,
+	depth int,
+) *TreeNode {
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "{…}")
+	}
+
+	keys := make([]protoreflect.MapKey, 0, m.Len())
+	m.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	slices.SortFunc(keys, compareProtoMapKeys)
+
+	shown := len(keys)
+	if h.limits.MaxMapEntries > 0 && shown > h.limits.MaxMapEntries {
+		shown = h.limits.MaxMapEntries
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindGroup})
+	for _, k := range keys[:shown] {
+		node.Children = append(node.Children, h.buildProtoValue(k.String(), fd.MapValue(), m.Get(k), depth+1))
+	}
+	if shown < len(keys) {
+		node.Children = append(node.Children, h.summaryNode("", moreItemsText(len(keys)-shown, "entries")))
+	}
+
+	return node
+}
+
+// buildProtoValue renders a singular value of the field.
+func (h *irBuilder) buildProtoValue(
+	key string,
+	fd protoreflect.FieldDescriptor,
+	v protoreflect.Value,
+	depth int,
+) *TreeNode {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return h.buildProtoTree(key, v.Message(), depth)
+
+	case protoreflect.EnumKind:
+		num := v.Enum()
+		if ev := fd.Enum().Values().ByNumber(num); ev != nil {
+			return h.node(TreeNode{Key: key, Kind: KindString, RawDisplay: true, Value: string(ev.Name())})
+		}
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: strconv.Itoa(int(num))})
+
+	case protoreflect.BytesKind:
+		data := v.Bytes()
+		if !isPrintableText(data) && h.heuristic(key, HeuristicHex) {
+			return h.binaryNode(key, data)
+		}
+		return h.node(TreeNode{Key: key, Kind: KindString, Value: h.limitString(string(data))})
+
+	case protoreflect.StringKind:
+		return h.buildIRTree(key, slog.StringValue(v.String()), depth)
+
+	case protoreflect.BoolKind:
+		return h.node(TreeNode{Key: key, Kind: KindBool, Value: v.String()})
+
+	default:
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: v.String()})
+	}
+}
+
+// buildProtoWellKnown renders google.protobuf well known types: timestamps and durations
+// as time values, wrappers as their values and Any as the message it holds.
+func (h *irBuilder) buildProtoWellKnown(key string, m protoreflect.Message, depth int) (*TreeNode, bool) {
+	desc := m.Descriptor()
+	if desc.ParentFile().Package() != "google.protobuf" {
+		return nil, false
+	}
+
+	fields := desc.Fields()
+	switch desc.Name() {
+	case "Timestamp":
+		secs := m.Get(fields.ByName("seconds")).Int()
+		nanos := m.Get(fields.ByName("nanos")).Int()
+		return h.buildIRTree(key, slog.TimeValue(time.Unix(secs, nanos).UTC()), depth), true
+
+	case "Duration":
+		secs := m.Get(fields.ByName("seconds")).Int()
+		nanos := m.Get(fields.ByName("nanos")).Int()
+		return h.buildIRTree(key, slog.DurationValue(time.Duration(secs)*time.Second+time.Duration(nanos)), depth), true
+
+	case "DoubleValue", "FloatValue", "Int64Value", "UInt64Value", "Int32Value", "UInt32Value",
+		"BoolValue", "StringValue", "BytesValue":
+		fd := fields.ByName("value")
+		return h.buildProtoValue(key, fd, m.Get(fd), depth), true
+
+	case "Any":
+		url := m.Get(fields.ByName("type_url")).String()
+		mt, err := protoregistry.GlobalTypes.FindMessageByURL(url)
+		if err != nil {
+			return nil, false
+		}
+
+		inner := mt.New()
+		if err := proto.Unmarshal(m.Get(fields.ByName("value")).Bytes(), inner.Interface()); err != nil {
+			return nil, false
+		}
+
+		node := h.buildProtoTree(key, inner, depth)
+		if node.Kind == KindGroup {
+			typeNode := h.node(TreeNode{Key: "@type", Kind: KindString, RawDisplay: true, Value: string(inner.Descriptor().FullName())})
+			node.Children = slices.Insert(node.Children, 0, typeNode)
+		}
+		return node, true
+	}
+
+	return nil, false
+}
+
+func compareProtoMapKeys(a, b protoreflect.MapKey) int {
+	switch x := a.Interface().(type) {
+	case int32:
+		return cmp.Compare(x, b.Interface().(int32))
+	case int64:
+		return cmp.Compare(x, b.Interface().(int64))
+	case uint32:
+		return cmp.Compare(x, b.Interface().(uint32))
+	case uint64:
+		return cmp.Compare(x, b.Interface().(uint64))
+	case bool:
+		return cmp.Compare(strconv.FormatBool(x), strconv.FormatBool(b.Bool()))
+	default:
+		return cmp.Compare(a.String(), b.String())
+	}
+}
@@ -0,0 +1,385 @@
+package errorsctx
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	logValuerType     = reflect.TypeFor[slog.LogValuer]()
+	errorType         = reflect.TypeFor[error]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	protoMessageType  = reflect.TypeFor[proto.Message]()
+)
+
+// reflectVisit identifies a reference being rendered to detect cycles.
+type reflectVisit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// buildReflectTree walks Go values the way encoding/json sees them: exported struct fields
+// with respect to json tags, maps with sorted keys, slices, arrays and pointers.
+// Protobuf messages are rendered field by field through protoreflect. Values implementing
+// [slog.LogValuer], error, [json.Marshaler], [encoding.TextMarshaler] and [fmt.Stringer]
+// are rendered with these methods, in this order of preference.
+func (h *irBuilder) buildReflectTree(
+	key string,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) *TreeNode {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return h.node(TreeNode{Key: key, Kind: KindNull, Value: "null"})
+	}
+
+	if node, ok := h.buildReflectSpecial(key, v, depth); ok {
+		return node
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return h.node(TreeNode{Key: key, Kind: KindBool, Value: strconv.FormatBool(v.Bool())})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: strconv.FormatInt(v.Int(), 10)})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: strconv.FormatUint(v.Uint(), 10)})
+	case reflect.Float32, reflect.Float64:
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())})
+	case reflect.Complex64, reflect.Complex128:
+		return h.node(TreeNode{Key: key, Kind: KindNumber, Value: strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())})
+	case reflect.String:
+		if node, ok := h.formatValue(key, slog.StringValue(v.String())); ok {
+			return node
+		}
+		return h.node(TreeNode{Key: key, Kind: KindString, Value: h.limitString(v.String())})
+	case reflect.Pointer:
+		if v.IsNil() {
+			return h.node(TreeNode{Key: key, Kind: KindNull, Value: "null"})
+		}
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return h.node(TreeNode{Key: key, Kind: KindNull, Value: "null"})
+		}
+	case reflect.Struct, reflect.Array:
+	default:
+		// Каналы, функции и прочее выводим как есть
+		return h.node(TreeNode{Key: key, Kind: KindString, RawDisplay: true, Value: v.Type().String()})
+	}
+
+	// Ссылочные значения проверяем на циклы
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		visit := reflectVisit{ptr: v.Pointer(), typ: v.Type()}
+		if _, ok := visiting[visit]; ok {
+			return h.summaryNode(key, "<cycle>")
+		}
+		if visiting == nil {
+			visiting = map[reflectVisit]struct{}{}
+		}
+		visiting[visit] = struct{}{}
+		defer delete(visiting, visit)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		return h.buildReflectTree(key, v.Elem(), depth, visiting)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return h.buildReflectBytes(key, v)
+		}
+		return h.buildReflectArray(key, v, depth, visiting)
+	case reflect.Map:
+		return h.buildReflectMap(key, v, depth, visiting)
+	default:
+		return h.buildReflectStruct(key, v, depth, visiting)
+	}
+}
+
+// buildReflectSpecial renders values with special types and methods.
+func (h *irBuilder) buildReflectSpecial(key string, v reflect.Value, depth int) (*TreeNode, bool) {
+	typ := v.Type()
+	switch typ {
+	case timeType:
+		return h.buildIRTree(key, slog.TimeValue(v.Interface().(time.Time)), depth), true
+	case durationType:
+		return h.buildIRTree(key, slog.DurationValue(time.Duration(v.Int())), depth), true
+	}
+
+	if !v.CanInterface() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, false
+	}
+
+	if node, ok := h.formatValue(key, slog.AnyValue(v.Interface())); ok {
+		return node, true
+	}
+
+	switch {
+	case typ.Implements(protoMessageType):
+		return h.buildProtoTree(key, v.Interface().(proto.Message).ProtoReflect(), depth), true
+
+	case typ.Implements(logValuerType):
+		return h.buildIRTree(key, slog.AnyValue(v.Interface()).Resolve(), depth), true
+
+	case typ.Implements(errorType):
+		return h.buildIRTree(key, slog.AnyValue(v.Interface()), depth), true
+
+	case typ.Implements(jsonMarshalerType):
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil || !json.Valid(data) {
+			return nil, false
+		}
+		return h.buildIRTreeFromJSONBytes(key, data, depth), true
+
+	case typ.Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, false
+		}
+		return h.node(TreeNode{Key: key, Kind: KindString, Value: h.limitString(string(text))}), true
+
+	case typ.Implements(stringerType):
+		return h.node(TreeNode{Key: key, Kind: KindString, Value: h.limitString(v.Interface().(fmt.Stringer).String())}), true
+	}
+
+	return nil, false
+}
+
+func (h *irBuilder) buildReflectBytes(key string, v reflect.Value) *TreeNode {
+	data := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(data), v)
+	if !isPrintableText(data) && h.heuristic(key, HeuristicHex) {
+		return h.binaryNode(key, data)
+	}
+
+	return h.node(TreeNode{Key: key, Kind: KindString, Value: h.limitString(string(data))})
+}
+
+func (h *irBuilder) buildReflectArray(
+	key string,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) *TreeNode {
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "[…]")
+	}
+
+	length := v.Len()
+	shown := length
+	if h.limits.MaxArrayItems > 0 && shown > h.limits.MaxArrayItems {
+		shown = h.limits.MaxArrayItems
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindArray})
+	for i := range shown {
+		node.Children = append(node.Children, h.buildReflectTree(indexKey(i), v.Index(i), depth+1, visiting))
+	}
+	if shown < length {
+		node.Children = append(node.Children, h.summaryNode("", moreItemsText(length-shown, "items")))
+	}
+
+	return node
+}
+
+func (h *irBuilder) buildReflectMap(
+	key string,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) *TreeNode {
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "{…}")
+	}
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareMapKeys)
+
+	shown := len(keys)
+	if h.limits.MaxMapEntries > 0 && shown > h.limits.MaxMapEntries {
+		shown = h.limits.MaxMapEntries
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindGroup})
+	for _, k := range keys[:shown] {
+		node.Children = append(node.Children, h.buildReflectTree(mapKeyString(k), v.MapIndex(k), depth+1, visiting))
+	}
+	if shown < len(keys) {
+		node.Children = append(node.Children, h.summaryNode("", moreItemsText(len(keys)-shown, "entries")))
+	}
+
+	return node
+}
+
+func (h *irBuilder) buildReflectStruct(
+	key string,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) *TreeNode {
+	if h.depthExceeded(depth) {
+		return h.summaryNode(key, "{…}")
+	}
+
+	node := h.node(TreeNode{Key: key, Kind: KindGroup})
+	h.appendReflectFields(node, v, depth, visiting)
+	return node
+}
+
+// appendReflectFields adds exported fields of the struct as children of the node.
+// Fields of embedded structs without a json name are promoted like encoding/json does.
+func (h *irBuilder) appendReflectFields(
+	node *TreeNode,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) {
+	typ := v.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			if ft := field.Type; ft.Kind() == reflect.Struct {
+				h.appendReflectFields(node, fv, depth, visiting)
+				continue
+			} else if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct {
+				h.appendReflectEmbedded(node, field.Name, fv, depth, visiting)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if (hasTagOption(opts, "omitempty") && isEmptyValue(fv)) || (hasTagOption(opts, "omitzero") && fv.IsZero()) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if h.limits.MaxMapEntries > 0 && len(node.Children) >= h.limits.MaxMapEntries {
+			node.Children = append(node.Children, h.summaryNode("", "… more fields"))
+			return
+		}
+		node.Children = append(node.Children, h.buildReflectTree(name, fv, depth+1, visiting))
+	}
+}
+
+// appendReflectEmbedded promotes fields of the struct embedded by a pointer. Embedded pointers
+// can make cycles, like a struct embedding a pointer to its own type.
+func (h *irBuilder) appendReflectEmbedded(
+	node *TreeNode,
+	name string,
+	v reflect.Value,
+	depth int,
+	visiting map[reflectVisit]struct{},
+) {
+	if v.IsNil() {
+		return
+	}
+
+	if h.depthExceeded(depth + 1) {
+		node.Children = append(node.Children, h.summaryNode(name, "{…}"))
+		return
+	}
+
+	visit := reflectVisit{ptr: v.Pointer(), typ: v.Type()}
+	if _, ok := visiting[visit]; ok {
+		node.Children = append(node.Children, h.summaryNode(name, "<cycle>"))
+		return
+	}
+	if visiting == nil {
+		visiting = map[reflectVisit]struct{}{}
+	}
+	visiting[visit] = struct{}{}
+	defer delete(visiting, visit)
+
+	h.appendReflectFields(node, v.Elem(), depth+1, visiting)
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmptyValue mirrors the notion of empty values of encoding/json omitempty option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+func mapKeyString(k reflect.Value) string {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	default:
+		if k.CanInterface() {
+			return fmt.Sprint(k.Interface())
+		}
+		return k.Type().String()
+	}
+}
+
+// compareMapKeys orders numeric keys numerically and the rest by their string representation.
+func compareMapKeys(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	default:
+		return strings.Compare(mapKeyString(a), mapKeyString(b))
+	}
+}
@@ -0,0 +1,142 @@
+package errorsctx
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/internal/location"
+)
+
+const (
+	sourceCacheMaxFiles    = 64
+	sourceCacheMaxFileSize = 1 << 20
+)
+
+// WithSourceSnippets makes the renderer show source code lines around every location it meets,
+// the line of the location itself is highlighted. contextLines sets the number of lines shown
+// before and after it, values below 1 are interpreted as 2.
+//
+// Source files are read once and cached. The cache is bounded both by the number of files
+// and by the size of each file, larger files are never shown.
+//
+// It is meant to be used with [errors.InsertLocations] during development.
+func WithSourceSnippets(contextLines int) PrettyRendererOption {
+	if contextLines < 1 {
+		contextLines = 2
+	}
+
+	return func(h *SlogPrettyRenderer) {
+		h.snippetLines = contextLines
+		h.sources = newSourceCache(sourceCacheMaxFiles, sourceCacheMaxFileSize)
+	}
+}
+
+// appendSourceSnippet renders source lines around the given location below its node.
+func (h *SlogPrettyRenderer) appendSourceSnippet(buf []byte, loc string, fullStates []bool) []byte {
+	path, lineStr, ok := location.Split(errors.OriginalLocation(loc))
+	if !ok {
+		return buf
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return buf
+	}
+
+	lines := h.sources.lines(path)
+	if line < 1 || line > len(lines) {
+		return buf
+	}
+
+	from := max(1, line-h.snippetLines)
+	to := min(len(lines), line+h.snippetLines)
+	width := len(strconv.Itoa(to))
+
+	for i := from; i <= to; i++ {
+		buf = h.appendStackLineIndent(buf, fullStates)
+
+		num := strconv.Itoa(i)
+		if i == line {
+			buf = append(buf, h.color.errmeta...)
+			buf = append(buf, "> "...)
+		} else {
+			buf = append(buf, h.color.trace...)
+			buf = append(buf, "  "...)
+		}
+		for range width - len(num) {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, num...)
+		buf = append(buf, h.color.reset...)
+		buf = append(buf, h.color.link...)
+		buf = append(buf, " │ "...)
+		buf = append(buf, h.color.reset...)
+
+		if i == line {
+			buf = append(buf, h.color.bold...)
+		} else {
+			buf = append(buf, h.color.sttext...)
+		}
+		buf = appendEscaped(buf, strings.ReplaceAll(lines[i-1], "\t", "    "), false)
+		buf = append(buf, h.color.reset...)
+	}
+
+	return buf
+}
+
+// sourceCache keeps lines of recently shown source files.
+type sourceCache struct {
+	lock     sync.Mutex
+	maxFiles int
+	maxSize  int64
+	files    map[string][]string
+	order    []string
+}
+
+func newSourceCache(maxFiles int, maxSize int64) *sourceCache {
+	return &sourceCache{
+		maxFiles: maxFiles,
+		maxSize:  maxSize,
+		files:    make(map[string][]string, maxFiles),
+		order:    make([]string, 0, maxFiles),
+	}
+}
+
+// lines returns lines of the file. Files which cannot be read or are too large are
+// remembered as empty ones to not retry them on every record.
+func (c *sourceCache) lines(path string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if lines, ok := c.files[path]; ok {
+		return lines
+	}
+
+	lines := c.read(path)
+	if len(c.order) >= c.maxFiles {
+		delete(c.files, c.order[0])
+		c.order = append(c.order[:0], c.order[1:]...)
+	}
+	c.files[path] = lines
+	c.order = append(c.order, path)
+
+	return lines
+}
+
+func (c *sourceCache) read(path string) []string {
+	stat, err := os.Stat(path)
+	if err != nil || !stat.Mode().IsRegular() || stat.Size() > c.maxSize {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	data = bytes.TrimSuffix(data, []byte{'\n'})
+	return strings.Split(string(data), "\n")
+}
@@ -0,0 +1,137 @@
+package errorsctx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithFullStackTraces disables collapsing of runtime and standard library frames in stack traces.
+func WithFullStackTraces() PrettyRendererOption {
+	return func(h *SlogPrettyRenderer) {
+		h.fullStacks = true
+	}
+}
+
+// appendTraceback renders parsed traceback: panic messages first, then goroutines
+// with identical stacks grouped together.
+func (h *SlogPrettyRenderer) appendTraceback(buf []byte, tb *traceback, fullStates []bool) []byte {
+	for _, line := range tb.header {
+		buf = h.appendStackLineIndent(buf, fullStates)
+		switch {
+		case strings.HasPrefix(line, "panic:"), strings.HasPrefix(line, "fatal error:"):
+			buf = append(buf, h.color.error...)
+		case strings.HasPrefix(line, "[signal"):
+			buf = append(buf, h.color.errmeta...)
+		default:
+			buf = append(buf, h.color.sttext...)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, h.color.reset...)
+	}
+
+	tb.groupGoroutines()
+	for _, g := range tb.goroutines {
+		buf = h.appendStackLineIndent(buf, fullStates)
+		buf = append(buf, h.color.panic...)
+		if len(g.ids) > 1 {
+			buf = append(buf, " goroutines "...)
+		} else {
+			buf = append(buf, " goroutine "...)
+		}
+		buf = append(buf, strings.Join(g.ids, ", ")...)
+		buf = append(buf, " ["...)
+		buf = append(buf, g.state...)
+		buf = append(buf, "] "...)
+		buf = append(buf, h.color.reset...)
+
+		buf = h.appendTracebackFrames(buf, g.frames, fullStates)
+		if g.createdBy != nil {
+			buf = h.appendTracebackFrame(buf, g.createdBy, fullStates)
+		}
+	}
+
+	return buf
+}
+
+// appendTracebackFrames renders frames collapsing runs of runtime and standard library ones.
+func (h *SlogPrettyRenderer) appendTracebackFrames(buf []byte, frames []tracebackFrame, fullStates []bool) []byte {
+	for i := 0; i < len(frames); i++ {
+		if h.fullStacks || !frames[i].collapsible() {
+			buf = h.appendTracebackFrame(buf, &frames[i], fullStates)
+			continue
+		}
+
+		j := i + 1
+		for j < len(frames) && frames[j].collapsible() {
+			j++
+		}
+		if j-i == 1 {
+			buf = h.appendTracebackFrame(buf, &frames[i], fullStates)
+			continue
+		}
+
+		buf = h.appendStackLineIndent(buf, fullStates)
+		buf = append(buf, h.color.trace...)
+		buf = append(buf, "… "...)
+		buf = strconv.AppendInt(buf, int64(j-i), 10)
+		buf = append(buf, " runtime/stdlib frames"...)
+		buf = append(buf, h.color.reset...)
+		i = j - 1
+	}
+
+	return buf
+}
+
+func (h *SlogPrettyRenderer) appendTracebackFrame(buf []byte, frame *tracebackFrame, fullStates []bool) []byte {
+	buf = h.appendStackLineIndent(buf, fullStates)
+	if frame.raw != "" {
+		buf = append(buf, h.color.sttext...)
+		buf = append(buf, frame.raw...)
+		buf = append(buf, h.color.reset...)
+		return buf
+	}
+
+	if frame.createdIn != "" {
+		buf = append(buf, h.color.stdots...)
+		buf = append(buf, "created by "...)
+		buf = append(buf, h.color.reset...)
+	}
+
+	switch frame.origin {
+	case frameOriginMain:
+		buf = append(buf, h.color.bold...)
+		buf = append(buf, h.color.errstage...)
+	case frameOriginDependency:
+		buf = append(buf, h.color.key...)
+	default:
+		buf = append(buf, h.color.sttext...)
+	}
+	buf = append(buf, frame.function...)
+	buf = append(buf, h.color.reset...)
+
+	if frame.createdIn != "" {
+		buf = append(buf, h.color.stdots...)
+		buf = append(buf, " in "...)
+		buf = append(buf, frame.createdIn...)
+		buf = append(buf, h.color.reset...)
+	}
+
+	buf = append(buf, h.color.stdots...)
+	buf = append(buf, " -> "...)
+	buf = append(buf, h.color.reset...)
+	buf = append(buf, h.color.loc...)
+	buf = h.appendLocation(buf, frame.location())
+	buf = append(buf, h.color.reset...)
+
+	if frame.inlined() {
+		buf = append(buf, h.color.trace...)
+		buf = append(buf, " (inlined)"...)
+		buf = append(buf, h.color.reset...)
+	}
+
+	return buf
+}
+
+func (f *tracebackFrame) collapsible() bool {
+	return f.raw == "" && (f.origin == frameOriginRuntime || f.origin == frameOriginStdlib)
+}
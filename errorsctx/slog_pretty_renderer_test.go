@@ -0,0 +1,691 @@
+package errorsctx_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestSlogPrettyRendererLocationLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		loc      string
+		want     string
+	}{
+		{
+			name:     "vscode",
+			template: errorsctx.LocationLinkVSCode,
+			loc:      "/home/user/project/main.go:42",
+			want:     "\x1b]8;;vscode://file/home/user/project/main.go:42\x1b\\/home/user/project/main.go:42\x1b]8;;\x1b\\",
+		},
+		{
+			name:     "idea-with-spaces",
+			template: errorsctx.LocationLinkIDEA,
+			loc:      "/home/user/my project/main.go:7",
+			want:     "\x1b]8;;idea://open?file=/home/user/my%20project/main.go&line=7\x1b\\",
+		},
+		{
+			name:     "not-a-location",
+			template: errorsctx.LocationLinkFile,
+			loc:      "main.go:forty-two",
+			want:     "main.go:forty-two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(errorsctx.NewSlogPrettyRenderer(
+				&buf,
+				nil,
+				true,
+				0,
+				errorsctx.WithLocationLinks(tt.template),
+			))
+			log.Info("location", slog.String("@location", tt.loc), errorsctx.ForceTree())
+
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogPrettyRendererSourceSnippets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "source.go")
+	src := "package source\n\nfunc f() error {\n\treturn errors.New(\"failure\")\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0, errorsctx.WithSourceSnippets(1)))
+	log.Info("location", slog.String("@location", path+":4"), errorsctx.ForceTree())
+
+	out := buf.String()
+	for _, want := range []string{
+		"func f() error {",
+		"    return errors.New(\"failure\")",
+		"> 4",
+		"  5",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "package source") {
+		t.Errorf("output %q contains lines out of the snippet range", out)
+	}
+}
+
+func TestSlogPrettyRendererTrimmedLocations(t *testing.T) {
+	errors.InsertLocations()
+	errors.TrimLocations(errors.TrimModuleRoot())
+	defer errors.DoNotInsertLocations()
+	defer errors.TrimLocations(nil)
+
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0, errorsctx.WithSourceSnippets(1)))
+	log.Error("trimmed", slog.Any("err", errors.New("trimmed location error")))
+
+	out := buf.String()
+	for _, want := range []string{
+		"errorsctx/slog_pretty_renderer_test.go:",
+		`log.Error("trimmed", slog.Any("err", errors.New("trimmed location error")))`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "/errorsctx/slog_pretty_renderer_test.go:") {
+		t.Errorf("output %q contains untrimmed location", out)
+	}
+}
+
+func TestSlogPrettyRendererStackTraces(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "goroutines.txt",
+			want: []string{
+				"panic: worker got its own id",
+				" goroutines 6, 8 [chan receive] ",
+				"… 2 runtime/stdlib frames",
+				"created by ",
+			},
+		},
+		{
+			file: "stack.txt",
+			want: []string{
+				"net/http.HandlerFunc.ServeHTTP",
+				" (inlined)",
+			},
+		},
+		{
+			file: "unknown.txt",
+			want: []string{
+				`File "/srv/app/main.py", line 12, in <module>`,
+				"ValueError: bad value",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "tracebacks", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0))
+			log.Info("stack", slog.String("stack", string(data)))
+
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output %q does not contain %q", out, want)
+				}
+			}
+		})
+	}
+}
+
+type userID int
+
+func TestSlogPrettyRendererFormatters(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithKeyFormatter("query", errorsctx.SQLFormatter()),
+		errorsctx.WithKeyFormatter("*url", errorsctx.URLFormatter()),
+		errorsctx.WithTypeFormatter[userID](errorsctx.ValueFormatterFunc(
+			func(key string, value slog.Value) (*errorsctx.TreeNode, bool) {
+				return &errorsctx.TreeNode{
+					Value: "user#" + value.String(),
+					Kind:  errorsctx.KindString,
+				}, true
+			},
+		)),
+		errorsctx.WithoutHeuristics("token", errorsctx.HeuristicBase64),
+	))
+	log.Info(
+		"formatters",
+		slog.String("query", "select id, name from users where name = 'from where' order by id"),
+		slog.String("callback-url", "https://example.com/api/v1?b=2&a=1%2B1"),
+		slog.Any("user", userID(42)),
+		slog.String("token", "dGVzdA=="),
+		slog.String("payload", `{"token": "dGVzdA==", "data": "SGVsbG8gV29ybGQh"}`),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"├── query\n│     SELECT id, name\n│     FROM users\n│     WHERE name = 'from where'\n│     ORDER BY id\n",
+		"├── callback-url\n│  ├── scheme: \"https\"\n│  ├── host: \"example.com\"",
+		"│     ├── a: \"1+1\"\n│     └── b: \"2\"",
+		`user: "user#42"`,
+		`token: "dGVzdA=="`,
+		`data: "Hello World!"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+		}
+	}
+	if strings.Contains(out, `token: "test"`) {
+		t.Errorf("output\n%s\nhas base64 decoded token", out)
+	}
+}
+
+func TestSlogPrettyRendererFormattersCompact(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithKeyFormatter("query", errorsctx.SQLFormatter()),
+	))
+	log.Info("q", slog.String("query", "select id from users where id = 1"))
+
+	out := stripANSI(buf.String())
+	if want := "query\n      SELECT id\n      FROM users\n      WHERE id = 1\n"; !strings.Contains(out, want) {
+		t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+	}
+}
+
+func TestSlogPrettyRendererYAML(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithKeyFormatter("*.yaml", errorsctx.YAMLFormatter()),
+	))
+	log.Info(
+		"config",
+		slog.String("app.yaml", `# application config
+---
+name: "demo app"
+replicas: 3
+debug: false
+tags: [web, 'edge']
+limits: {cpu: 500m, memory: 1Gi}
+env:
+- name: LOG_LEVEL # comment
+  value: debug
+- name: EMPTY
+script: |
+  echo start
+    run --fast
+`),
+		slog.String("plain.yaml", "just a string"),
+		slog.String("colon.yaml", "[:]"),
+		slog.String("nested-colon.yaml", "a: [x, : ]"),
+		slog.String("unclosed.yaml", "{a: ["),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"├── app.yaml\n",
+		`│  ├── name: "demo app"`,
+		"│  ├── replicas: 3\n",
+		"│  ├── debug: false\n",
+		"│  ├── tags\n│  │  ├── [0]: web\n│  │  └── [1]: edge\n",
+		"│  ├── limits\n│  │  ├── cpu: \"500m\"\n│  │  └── memory: \"1Gi\"\n",
+		"│  │  ├── [0]\n│  │  │  ├── name: \"LOG_LEVEL\"\n│  │  │  └── value: \"debug\"\n",
+		"│  │  └── [1]\n│  │     └── name: \"EMPTY\"\n",
+		"echo start\n",
+		"  run --fast\n",
+		`plain.yaml: "just a string"`,
+		`colon.yaml: "[:]"`,
+		`nested-colon.yaml: "a: [x, : ]"`,
+		`unclosed.yaml: "{a: ["`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+		}
+	}
+}
+
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b]8;;[^\x1b]*\x1b\\\\")
+
+func stripANSI(s string) string {
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+func TestSlogPrettyRendererHumanize(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithRelativeTime("started"),
+		errorsctx.WithHumanDurations(),
+		errorsctx.WithByteSizes("*-size"),
+		errorsctx.WithTimeDelta(),
+	))
+	log.Info("first")
+	log.Info(
+		"humanized",
+		slog.Time("started", time.Now().Add(-3*time.Second)),
+		slog.Time("deadline", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+		slog.Duration("elapsed", 1234567891*time.Nanosecond),
+		slog.Duration("skew", -5*time.Millisecond),
+		slog.Int64("body-size", 1572864),
+		slog.Int64("count", 1572864),
+		slog.Any("total", errorsctx.ByteSize(1023)),
+		errorsctx.ForceTree(),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []*regexp.Regexp{
+		regexp.MustCompile(`\d{3} \+\S+ INFO humanized`),
+		regexp.MustCompile(`started: 3(\.\d+)?s ago`),
+		regexp.MustCompile(`deadline: "2026-01-02 03:04:05.000"`),
+		regexp.MustCompile(`elapsed: 1.23s\n`),
+		regexp.MustCompile(`skew: -5ms\n`),
+		regexp.MustCompile(`body-size: 1.5 MiB\n`),
+		regexp.MustCompile(`count: 1572864\n`),
+		regexp.MustCompile(`total: 1023 B\n`),
+	} {
+		if !want.MatchString(out) {
+			t.Errorf("output\n%s\ndoes not match %s", out, want)
+		}
+	}
+	if strings.Contains(out, "+") && strings.Index(out, "+") < strings.Index(out, "first") {
+		t.Errorf("output\n%s\nhas a time delta for the first record", out)
+	}
+}
+
+func TestSlogPrettyRendererLimits(t *testing.T) {
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithLimits(errorsctx.RenderLimits{
+			MaxArrayItems: 3,
+			MaxMapEntries: 2,
+			MaxDepth:      3,
+			MaxStringLen:  8,
+		}),
+	))
+	log.Info(
+		"limits",
+		slog.Any("items", items),
+		slog.String("object", `{"a": 1, "b": {"c": {"d": {"e": 1}}, "f": [[1]]}, "g": 3, "h": 4}`),
+		slog.String("text", "Привет, мир!"),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"├── items\n│  ├── [0]: 0\n│  ├── [1]: 1\n│  ├── [2]: 2\n│  └── … 97 more items\n",
+		"│  ├── a: 1\n│  ├── b\n│  │  ├── c\n│  │  │  └── d: {…}\n│  │  └── f\n│  │     └── [0]: […]\n│  └── … 2 more entries\n",
+		`text: "Прив… (21 bytes)"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+		}
+	}
+
+	// Записи с малым контекстом выводятся в одну строку, но ограничения действуют и там.
+	buf.Reset()
+	log.Info("compact", slog.String("text", "Привет, мир!"))
+	if out, want := stripANSI(buf.String()), `{"text": "Прив… (21 bytes)"}`; !strings.Contains(out, want) {
+		t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+	}
+}
+
+func TestSlogPrettyRendererHexDump(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(
+		&buf,
+		nil,
+		true,
+		0,
+		errorsctx.WithHexDump(64, "packet"),
+	))
+	log.Info(
+		"binary",
+		slog.Any("packet", append([]byte("Hello World!\n"), 0, 1, 2, 0xff, 'x')),
+		slog.Any("short", []byte{0, 1, 2}),
+		slog.Any("large", make([]byte, 64)),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"├── packet: 18 bytes\n" +
+			"│     00000000: 4865 6c6c 6f20 576f 726c 6421 0a00 0102  Hello World!....\n" +
+			"│     00000010: ff78                                     .x\n",
+		"├── short: hex(000102)\n",
+		"└── large: 64 bytes\n",
+		"      00000030: 0000 0000 0000 0000 0000 0000 0000 0000  ................",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+		}
+	}
+}
+
+type reflectNode struct {
+	Name     string         `json:"name"`
+	Secret   string         `json:"-"`
+	Empty    string         `json:"empty,omitempty"`
+	Labels   map[string]int `json:"labels"`
+	Sizes    map[int]string `json:"sizes"`
+	Next     *reflectNode   `json:"next"`
+	Owner    reflectOwner   `json:"owner"`
+	internal int
+	reflectEmbedded
+}
+
+type reflectEmbedded struct {
+	Region string `json:"region"`
+}
+
+type reflectOwner struct {
+	id int
+}
+
+func (o reflectOwner) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", o.id))
+}
+
+func TestSlogPrettyRendererReflection(t *testing.T) {
+	node := &reflectNode{
+		Name:            "root",
+		Secret:          "password",
+		Labels:          map[string]int{"b": 2, "a": 1},
+		Sizes:           map[int]string{10: "ten", 9: "nine"},
+		Owner:           reflectOwner{id: 42},
+		internal:        1,
+		reflectEmbedded: reflectEmbedded{Region: "eu"},
+	}
+	node.Next = node
+
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0))
+	log.Info("reflection", slog.Any("node", node))
+
+	out := stripANSI(buf.String())
+	want := "└── node\n" +
+		"   ├── name: \"root\"\n" +
+		"   ├── labels\n" +
+		"   │  ├── a: 1\n" +
+		"   │  └── b: 2\n" +
+		"   ├── sizes\n" +
+		"   │  ├── 9: \"nine\"\n" +
+		"   │  └── 10: \"ten\"\n" +
+		"   ├── next: <cycle>\n" +
+		"   ├── owner\n" +
+		"   │  └── id: 42\n" +
+		"   └── region: \"eu\"\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+	}
+	if strings.Contains(out, "password") || strings.Contains(out, "internal") {
+		t.Errorf("output\n%s\nexposes ignored fields", out)
+	}
+}
+
+type selfEmbedded struct {
+	*selfEmbedded
+	Name string
+}
+
+func TestSlogPrettyRendererReflectionEmbeddedCycle(t *testing.T) {
+	node := &selfEmbedded{Name: "self"}
+	node.selfEmbedded = node
+
+	for name, opts := range map[string][]errorsctx.PrettyRendererOption{
+		"default":   nil,
+		"max-depth": {errorsctx.WithLimits(errorsctx.RenderLimits{MaxDepth: 3})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0, opts...))
+			log.Info("cycle", slog.Any("node", node), slog.Any("value", *node))
+
+			out := stripANSI(buf.String())
+			for _, want := range []string{
+				"├── node\n│  ├── selfEmbedded: <cycle>\n│  └── Name: \"self\"\n",
+				"└── value\n   ├── selfEmbedded: <cycle>\n",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSlogPrettyRendererProtobuf(t *testing.T) {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("id"),
+		Number: proto.Int32(1),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+		Type:   descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+		Options: &descriptorpb.FieldOptions{
+			Deprecated: proto.Bool(true),
+		},
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0))
+	log.Info(
+		"protobuf",
+		slog.Any("field", field),
+		slog.Any("created", timestamppb.New(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))),
+		slog.Any("timeout", durationpb.New(1500*time.Millisecond)),
+		slog.Any("err", errors.New("invalid field").Any("field", field)),
+	)
+
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"├── field\n" +
+			"│  ├── name: \"id\"\n" +
+			"│  ├── number: 1\n" +
+			"│  ├── label: LABEL_REPEATED\n" +
+			"│  ├── type: TYPE_INT64\n" +
+			"│  └── options\n" +
+			"│     └── deprecated: true\n",
+		"├── created: \"2024-03-01 12:00:00.000\"\n",
+		"├── timeout: 1.5s\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+		}
+	}
+	if strings.Count(out, "label: LABEL_REPEATED") != 2 {
+		t.Errorf("output\n%s\nmust render protobuf message in the error context", out)
+	}
+}
+
+func TestSlogPrettyRendererEscaping(t *testing.T) {
+	const (
+		clearScreen = "\x1b[2J\x1b[H"
+		fakeLine    = "ok\r2024-01-01 00:00:00.000 INFO fake"
+		spoofed     = "user‮gnp.exe"
+		invalid     = "bad\xff\xfe"
+		c1          = "csi\u009b31m"
+	)
+
+	for _, tc := range []struct {
+		name   string
+		log    func(log *slog.Logger)
+		wants  []string
+		trusts []string
+	}{
+		{
+			name: "compact",
+			log: func(log *slog.Logger) {
+				log.Info("login"+clearScreen, slog.String("user"+clearScreen, fakeLine))
+			},
+			wants: []string{
+				`login\x1b[2J\x1b[H {"user\x1b[2J\x1b[H": "ok\r2024-01-01 00:00:00.000 INFO fake"}`,
+			},
+		},
+		{
+			name: "tree",
+			log: func(log *slog.Logger) {
+				log.Info(
+					"login",
+					slog.String("name", spoofed),
+					slog.String("raw", invalid),
+					slog.String("c1", c1),
+					slog.String("multiline", "first\nsecond\x07"),
+					slog.Any("err", errors.New("denied"+clearScreen).Str("token", clearScreen)),
+				)
+			},
+			wants: []string{
+				`├── name: "user\u202egnp.exe"`,
+				`├── raw: "bad\xff\xfe"`,
+				`├── c1: "csi\u009b31m"`,
+				`├── multiline: "first\nsecond\x07"`,
+				`├── @text: denied\x1b[2J\x1b[H`,
+				`token: "\x1b[2J\x1b[H"`,
+			},
+		},
+		{
+			name: "stack",
+			log: func(log *slog.Logger) {
+				log.Info(
+					"panic",
+					slog.String("stack", "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:10 +0x1d\x1b[31m\nfake\x1b]0;title\x07"),
+				)
+			},
+			wants: []string{
+				`fake\x1b]0;title\x07`,
+			},
+		},
+		{
+			name: "trusted",
+			log: func(log *slog.Logger) {
+				log.Info("colored", slog.String("diff", "\x1b[32m+added\x1b[0m"), slog.String("user", clearScreen))
+			},
+			wants: []string{
+				`{"diff": "+added", "user": "\x1b[2J\x1b[H"}`,
+			},
+			trusts: []string{"diff"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0, errorsctx.WithTrustedKeys(tc.trusts...)))
+			if tc.trusts == nil {
+				log = slog.New(errorsctx.NewSlogPrettyRenderer(&buf, nil, true, 0))
+			}
+			tc.log(log)
+
+			out := stripANSI(buf.String())
+			for _, want := range tc.wants {
+				if !strings.Contains(out, want) {
+					t.Errorf("output\n%s\ndoes not contain\n%s", out, want)
+				}
+			}
+			for _, c := range out {
+				if c == '\r' || c == '\x07' || c == '‮' || c == '\u009b' || c == utf8.RuneError {
+					t.Errorf("output\n%q\ncontains unescaped control character %q", out, c)
+				}
+			}
+		})
+	}
+}
+
+func TestSlogPrettyRendererReuse(t *testing.T) {
+	records := []func(log *slog.Logger){
+		func(log *slog.Logger) {
+			log.Info(
+				"large",
+				slog.Any("obj", map[string]any{"list": []int{1, 2, 3}, "nested": map[string]string{"a": "b"}}),
+				slog.String("json", `{"a": [1, 2, {"b": "c"}], "d": null}`),
+				slog.Any("err", errors.Wrap(errors.New("inner").Int("code", 1), "outer").Str("op", "read")),
+			)
+		},
+		func(log *slog.Logger) {
+			log.Info("small", slog.Any("list", []string{"x"}), slog.Group("group", slog.Int("n", 1)), errorsctx.ForceTree())
+		},
+		func(log *slog.Logger) {
+			log.Info("json", slog.String("json", `[{"a": 1}]`))
+		},
+	}
+
+	render := func(log *slog.Logger, record func(log *slog.Logger), buf *bytes.Buffer) string {
+		buf.Reset()
+		record(log)
+		_, out, _ := strings.Cut(stripANSI(buf.String()), " INFO ")
+		return out
+	}
+
+	var shared bytes.Buffer
+	sharedLog := slog.New(errorsctx.NewSlogPrettyRenderer(&shared, nil, true, 0))
+	for range 3 {
+		for i, record := range records {
+			var fresh bytes.Buffer
+			want := render(slog.New(errorsctx.NewSlogPrettyRenderer(&fresh, nil, true, 0)), record, &fresh)
+			if got := render(sharedLog, record, &shared); got != want {
+				t.Errorf("record %d rendered with reused IR\n%s\ndiffers from\n%s", i, got, want)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	discardLog := slog.New(errorsctx.NewSlogPrettyRenderer(io.Discard, nil, true, 0))
+	for range 8 {
+		wg.Go(func() {
+			for _, record := range records {
+				record(discardLog)
+			}
+		})
+	}
+	wg.Wait()
+}
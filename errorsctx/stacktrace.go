@@ -0,0 +1,315 @@
+package errorsctx
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/sirkon/errors/internal/location"
+)
+
+// traceback is a parsed Go traceback: panic output, goroutine dump or [debug.Stack] result.
+type traceback struct {
+	// header holds lines preceding goroutines, such as panic messages and signal info.
+	header     []string
+	goroutines []*tracebackGoroutine
+}
+
+type tracebackGoroutine struct {
+	ids       []string
+	state     string
+	frames    []tracebackFrame
+	createdBy *tracebackFrame
+}
+
+type tracebackFrame struct {
+	function string
+	args     string
+	file     string
+	line     string
+	// offset is the +0x… program counter offset. Inlined frames have none.
+	offset    string
+	origin    frameOrigin
+	createdIn string
+	// raw holds lines which are not frames: "...N frames elided..." and unknown ones.
+	raw string
+}
+
+func (f *tracebackFrame) inlined() bool {
+	return f.raw == "" && f.offset == "" && f.args == "..."
+}
+
+func (f *tracebackFrame) location() string {
+	return f.file + ":" + f.line
+}
+
+// frameOrigin classifies a frame by the package of its function.
+type frameOrigin int
+
+const (
+	frameOriginDependency frameOrigin = iota
+	frameOriginMain
+	frameOriginStdlib
+	frameOriginRuntime
+)
+
+var mainModulePath = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	return info.Main.Path
+})
+
+// parseTraceback parses Go traceback text. Frames of the mainModule and of the main package
+// are marked as such. It returns false if the text does not look like a Go traceback.
+func parseTraceback(text, mainModule string) (*traceback, bool) {
+	var res traceback
+	var current *tracebackGoroutine
+	var pending *tracebackFrame
+
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" {
+			continue
+		}
+
+		if ids, state, ok := parseGoroutineHeader(line); ok {
+			flushPendingFrame(current, pending)
+			pending = nil
+			current = &tracebackGoroutine{
+				ids:   []string{ids},
+				state: state,
+			}
+			res.goroutines = append(res.goroutines, current)
+			continue
+		}
+
+		if current == nil {
+			res.header = append(res.header, line)
+			continue
+		}
+
+		if pending != nil {
+			if file, lineNo, offset, ok := parseFrameLocation(line); ok {
+				pending.file = file
+				pending.line = lineNo
+				pending.offset = offset
+				if pending.createdIn != "" {
+					current.createdBy = pending
+				} else {
+					current.frames = append(current.frames, *pending)
+				}
+				pending = nil
+				continue
+			}
+
+			flushPendingFrame(current, pending)
+			pending = nil
+		}
+
+		if fn, in, ok := parseCreatedBy(line); ok {
+			pending = &tracebackFrame{
+				function:  fn,
+				createdIn: in,
+				origin:    functionOrigin(fn, mainModule),
+			}
+			continue
+		}
+
+		if fn, args, ok := parseFrameFunction(line); ok {
+			pending = &tracebackFrame{
+				function: fn,
+				args:     args,
+				origin:   functionOrigin(fn, mainModule),
+			}
+			continue
+		}
+
+		current.frames = append(current.frames, tracebackFrame{raw: line})
+	}
+	flushPendingFrame(current, pending)
+
+	if len(res.goroutines) == 0 {
+		return nil, false
+	}
+	for _, g := range res.goroutines {
+		for _, frame := range g.frames {
+			if frame.raw == "" {
+				return &res, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// flushPendingFrame saves a function line not followed by a location as a raw line.
+func flushPendingFrame(g *tracebackGoroutine, pending *tracebackFrame) {
+	if g == nil || pending == nil {
+		return
+	}
+
+	raw := pending.function + "(" + pending.args + ")"
+	if pending.createdIn != "" {
+		raw = "created by " + pending.function + " in " + pending.createdIn
+	}
+	g.frames = append(g.frames, tracebackFrame{raw: raw})
+}
+
+// groupGoroutines merges goroutines with identical states and stacks into one entry.
+func (t *traceback) groupGoroutines() {
+	res := t.goroutines[:0]
+	seen := make(map[string]*tracebackGoroutine, len(t.goroutines))
+	for _, g := range t.goroutines {
+		sig := g.signature()
+		if prev, ok := seen[sig]; ok {
+			prev.ids = append(prev.ids, g.ids...)
+			continue
+		}
+
+		seen[sig] = g
+		res = append(res, g)
+	}
+	t.goroutines = res
+}
+
+func (g *tracebackGoroutine) signature() string {
+	var sig strings.Builder
+	sig.WriteString(g.state)
+	for _, frame := range g.frames {
+		sig.WriteByte('\n')
+		sig.WriteString(frame.function)
+		sig.WriteByte(' ')
+		sig.WriteString(frame.location())
+		sig.WriteString(frame.raw)
+	}
+	if g.createdBy != nil {
+		sig.WriteString("\ncreated by ")
+		sig.WriteString(g.createdBy.function)
+		sig.WriteByte(' ')
+		sig.WriteString(g.createdBy.location())
+	}
+
+	return sig.String()
+}
+
+// parseGoroutineHeader parses lines like
+//
+//	goroutine 1 [running]:
+//	goroutine 1 gp=0xc000002380 m=0 mp=0x5f8a0 [running]:
+func parseGoroutineHeader(line string) (id, state string, ok bool) {
+	rest, ok := strings.CutPrefix(line, "goroutine ")
+	if !ok || !strings.HasSuffix(rest, "]:") {
+		return "", "", false
+	}
+
+	idEnd := strings.IndexByte(rest, ' ')
+	if idEnd <= 0 || !isDecimal(rest[:idEnd]) {
+		return "", "", false
+	}
+
+	stateStart := strings.IndexByte(rest, '[')
+	if stateStart < 0 {
+		return "", "", false
+	}
+
+	return rest[:idEnd], rest[stateStart+1 : len(rest)-2], true
+}
+
+// parseCreatedBy parses lines like "created by main.main in goroutine 1".
+func parseCreatedBy(line string) (fn, in string, ok bool) {
+	rest, ok := strings.CutPrefix(line, "created by ")
+	if !ok || rest == "" {
+		return "", "", false
+	}
+
+	fn, in, found := strings.Cut(rest, " in ")
+	if !found {
+		// Older Go versions do not report the parent goroutine.
+		return rest, "?", true
+	}
+
+	return fn, in, true
+}
+
+// parseFrameFunction parses lines like "main.(*store).get(0x4540f5?, {0x4800bb, 0x4})".
+func parseFrameFunction(line string) (fn, args string, ok bool) {
+	if !strings.HasSuffix(line, ")") {
+		return "", "", false
+	}
+
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				fn = line[:i]
+				if fn == "" || strings.ContainsAny(fn, " \t") {
+					return "", "", false
+				}
+
+				return fn, line[i+1 : len(line)-1], true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// parseFrameLocation parses lines like "/path/to/file.go:12 +0x1d fp=0x… sp=0x… pc=0x…".
+func parseFrameLocation(line string) (file, lineNo, offset string, ok bool) {
+	loc := line
+	if idx := strings.Index(loc, " +0x"); idx >= 0 {
+		offset, _, _ = strings.Cut(loc[idx+1:], " ")
+		loc = loc[:idx]
+	} else if idx := strings.Index(loc, " fp="); idx >= 0 {
+		loc = loc[:idx]
+	}
+
+	idx := strings.LastIndexByte(loc, ':')
+	if idx <= 0 || !isDecimal(loc[idx+1:]) {
+		return "", "", "", false
+	}
+
+	return loc[:idx], loc[idx+1:], offset, true
+}
+
+// functionOrigin classifies function by its package path.
+func functionOrigin(fn, mainModule string) frameOrigin {
+	pkg := location.FunctionPackage(fn)
+	switch {
+	case pkg == "main":
+		return frameOriginMain
+	case mainModule != "" && (pkg == mainModule || strings.HasPrefix(pkg, mainModule+"/")):
+		return frameOriginMain
+	case pkg == "" || pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") || strings.HasPrefix(pkg, "internal/runtime"):
+		return frameOriginRuntime
+	}
+
+	first, _, _ := strings.Cut(pkg, "/")
+	if !strings.Contains(first, ".") {
+		return frameOriginStdlib
+	}
+
+	return frameOriginDependency
+}
+
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,180 @@
+package errorsctx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTraceback(t *testing.T) {
+	type frameCheck struct {
+		goroutine int
+		frame     int
+		function  string
+		location  string
+		origin    frameOrigin
+		inlined   bool
+	}
+
+	tests := []struct {
+		file       string
+		header     []string
+		goroutines int
+		grouped    int
+		frames     []frameCheck
+		createdBy  string
+		elided     bool
+	}{
+		{
+			file:       "simple.txt",
+			header:     []string{`panic: missing key "user"`},
+			goroutines: 1,
+			grouped:    1,
+			frames: []frameCheck{
+				{0, 0, "main.(*store).get", "/tmp/tb/simple/main.go:10", frameOriginMain, false},
+				{0, 1, "main.main", "/tmp/tb/simple/main.go:17", frameOriginMain, false},
+			},
+		},
+		{
+			file: "recovered.txt",
+			header: []string{
+				"panic: runtime error: index out of range [5] with length 3 [recovered]",
+				"panic: handler failed",
+			},
+			goroutines: 1,
+			grouped:    1,
+			frames: []frameCheck{
+				{0, 1, "panic", "/usr/local/go/src/runtime/panic.go:859", frameOriginRuntime, false},
+				{0, 2, "main.process", "/tmp/tb/recovered/main.go:15", frameOriginMain, true},
+			},
+		},
+		{
+			file:       "goroutines.txt",
+			header:     []string{"panic: worker got its own id"},
+			goroutines: 4,
+			grouped:    3,
+			frames: []frameCheck{
+				{1, 0, "sync.runtime_SemacquireWaitGroup", "/usr/local/go/src/runtime/sema.go:114", frameOriginStdlib, false},
+				{1, 1, "sync.(*WaitGroup).Wait", "/usr/local/go/src/sync/waitgroup.go:206", frameOriginStdlib, false},
+			},
+			createdBy: "main.main",
+		},
+		{
+			file:       "stack.txt",
+			goroutines: 1,
+			grouped:    1,
+			frames: []frameCheck{
+				{0, 0, "runtime/debug.Stack", "/usr/local/go/src/runtime/debug/stack.go:26", frameOriginRuntime, false},
+				{0, 2, "net/http.HandlerFunc.ServeHTTP", "/usr/local/go/src/net/http/server.go:2338", frameOriginStdlib, true},
+			},
+		},
+		{
+			file: "segv.txt",
+			header: []string{
+				"panic: runtime error: invalid memory address or nil pointer dereference",
+				"[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x47db13]",
+			},
+			goroutines: 1,
+			grouped:    1,
+			frames: []frameCheck{
+				{0, 0, "main.sum", "/tmp/tb/segv/main.go:6", frameOriginMain, false},
+			},
+		},
+		{
+			file:       "deep.txt",
+			header:     []string{"panic: assignment to entry in nil map"},
+			goroutines: 1,
+			grouped:    1,
+			elided:     true,
+		},
+		{
+			file:       "system.txt",
+			header:     []string{`panic: missing key "user"`},
+			goroutines: 5,
+			grouped:    5,
+			frames: []frameCheck{
+				{0, 1, "main.(*store).get", "/tmp/tb/simple/main.go:10", frameOriginMain, false},
+				{0, 4, "runtime.goexit", "/usr/local/go/src/runtime/asm_amd64.s:1264", frameOriginRuntime, false},
+			},
+			createdBy: "runtime.init.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "tracebacks", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tb, ok := parseTraceback(string(data), "example.com/tb")
+			if !ok {
+				t.Fatal("traceback was not recognized")
+			}
+
+			if len(tb.header) != len(tt.header) {
+				t.Fatalf("got header %q, want %q", tb.header, tt.header)
+			}
+			for i, line := range tt.header {
+				if tb.header[i] != line {
+					t.Errorf("header line %d: got %q, want %q", i, tb.header[i], line)
+				}
+			}
+
+			if len(tb.goroutines) != tt.goroutines {
+				t.Fatalf("got %d goroutines, want %d", len(tb.goroutines), tt.goroutines)
+			}
+
+			for _, check := range tt.frames {
+				frame := tb.goroutines[check.goroutine].frames[check.frame]
+				if frame.function != check.function {
+					t.Errorf("frame %d.%d: got function %q, want %q", check.goroutine, check.frame, frame.function, check.function)
+				}
+				if frame.location() != check.location {
+					t.Errorf("frame %d.%d: got location %q, want %q", check.goroutine, check.frame, frame.location(), check.location)
+				}
+				if frame.origin != check.origin {
+					t.Errorf("frame %d.%d: got origin %d, want %d", check.goroutine, check.frame, frame.origin, check.origin)
+				}
+				if frame.inlined() != check.inlined {
+					t.Errorf("frame %d.%d: got inlined %t, want %t", check.goroutine, check.frame, frame.inlined(), check.inlined)
+				}
+			}
+
+			var createdBy string
+			var elided bool
+			for _, g := range tb.goroutines {
+				if g.createdBy != nil && createdBy == "" {
+					createdBy = g.createdBy.function
+				}
+				for _, frame := range g.frames {
+					if frame.raw != "" {
+						elided = true
+					}
+				}
+			}
+			if tt.createdBy != "" && createdBy != tt.createdBy {
+				t.Errorf("got created by %q, want %q", createdBy, tt.createdBy)
+			}
+			if elided != tt.elided {
+				t.Errorf("got raw lines %t, want %t", elided, tt.elided)
+			}
+
+			tb.groupGoroutines()
+			if len(tb.goroutines) != tt.grouped {
+				t.Errorf("got %d goroutine groups, want %d", len(tb.goroutines), tt.grouped)
+			}
+		})
+	}
+}
+
+func TestParseTracebackUnknownFormat(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tracebacks", "unknown.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := parseTraceback(string(data), ""); ok {
+		t.Error("non-Go traceback must not be recognized")
+	}
+}
@@ -0,0 +1,261 @@
+// Package errorshttp converts errors into RFC 9457 problem details and back.
+//
+// Statuses of problems come from [Status] specs or from categories of errors, see [errors.CategoryOf].
+// Texts of errors are never shown to clients, the detail of a problem is given explicitly with
+// a [Detail] spec. Context values of errors are shown only for keys allowed with [WithKeys].
+//
+//	var ErrUserNotFound = errors.NewSentinel(
+//		"user not found",
+//		errors.WithSpec(errors.CategoryNotFound),
+//		errors.WithSpec(errorshttp.Detail("no such user")),
+//	)
+//
+//	w := errorshttp.NewWriter(errorshttp.WithKeys("user_id"), errorshttp.WithLogger(logger))
+//	mux.Handle("/users/{id}", w.Handler(getUser))
+package errorshttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/sirkon/errors"
+)
+
+// Status is a spec setting the HTTP status of the problem, it takes precedence over the category.
+// Only 4xx and 5xx statuses are used, the category decides for others.
+type Status int
+
+// valid checks if the status is an error one.
+func (s Status) valid() bool {
+	return s >= 400 && s <= 599
+}
+
+// Detail is a spec setting the detail of the problem. It is shown to clients, so it must not
+// contain anything internal.
+type Detail string
+
+// Type is a spec setting the type URI of the problem.
+type Type string
+
+// categoryMember is the extension member carrying the category of the error.
+const categoryMember = "category"
+
+// Writer writes errors as problem details.
+type Writer struct {
+	keys     []string
+	instance func(r *http.Request) string
+	logger   *slog.Logger
+}
+
+// Option tunes [Writer].
+type Option func(*Writer)
+
+// NewWriter creates a writer of problem details.
+func NewWriter(opts ...Option) *Writer {
+	res := &Writer{
+		instance: newInstance,
+	}
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return res
+}
+
+// WithKeys allows context values with the given keys to be shown as extension members
+// of problems. Values given on outer layers win. Keys of standard members are ignored.
+func WithKeys(keys ...string) Option {
+	return func(w *Writer) {
+		for _, key := range keys {
+			if !slices.Contains(problemMembers, key) && key != categoryMember {
+				w.keys = append(w.keys, key)
+			}
+		}
+	}
+}
+
+// WithInstance sets the function giving instance identifiers of problems. Default instances
+// are random URNs of UUID v4 form.
+func WithInstance(instance func(r *http.Request) string) Option {
+	return func(w *Writer) {
+		w.instance = instance
+	}
+}
+
+// WithLogger makes the writer log errors it writes, together with instances of their
+// problems, so a problem got by a client can be matched with the log record. Errors
+// with 5xx statuses are logged at the error level, others at the warning one.
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Writer) {
+		w.logger = logger
+	}
+}
+
+// Problem builds problem details of the error. Besides allowed context values, problems
+// have the "category" extension member with the name of the category of the error.
+func (w *Writer) Problem(r *http.Request, err error) *Problem {
+	status, ok := errors.AsSpec[Status](err)
+	if !ok || !status.valid() {
+		status = Status(errors.CategoryOf(err).HTTPStatus())
+	}
+
+	res := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(int(status)),
+		Status: int(status),
+		Extensions: map[string]any{
+			categoryMember: errors.CategoryOf(err).String(),
+		},
+	}
+	if typ, ok := errors.AsSpec[Type](err); ok {
+		res.Type = string(typ)
+	}
+	if detail, ok := errors.AsSpec[Detail](err); ok {
+		res.Detail = string(detail)
+	}
+	if w.instance != nil {
+		res.Instance = w.instance(r)
+	}
+
+	if len(w.keys) == 0 {
+		return res
+	}
+	e, ok := errors.AsType[*errors.Error](err)
+	if !ok {
+		return res
+	}
+	// Плоский контекст идёт изнутри наружу, так что внешние значения перезаписывают внутренние.
+	for _, attr := range errors.SLogFlatContext(e) {
+		if slices.Contains(w.keys, attr.Key) {
+			res.Extensions[attr.Key] = jsonValue(attr.Value)
+		}
+	}
+
+	return res
+}
+
+// Write writes problem details of the error into the response and returns them.
+func (w *Writer) Write(rw http.ResponseWriter, r *http.Request, err error) *Problem {
+	p := w.Problem(r, err)
+	if w.logger != nil {
+		level := slog.LevelWarn
+		if p.Status >= 500 {
+			level = slog.LevelError
+		}
+		w.logger.Log(
+			r.Context(),
+			level,
+			"request failed",
+			slog.Any("err", err),
+			slog.String("instance", p.Instance),
+			slog.Int("status", p.Status),
+		)
+	}
+
+	data, merr := p.MarshalJSON()
+	if merr != nil {
+		// Расширения берутся из контекста ошибки, не все значения кодируются в JSON.
+		p.Extensions = map[string]any{categoryMember: p.Extensions[categoryMember]}
+		data, _ = p.MarshalJSON()
+	}
+
+	rw.Header().Set("Content-Type", ContentType)
+	rw.WriteHeader(p.Status)
+	_, _ = rw.Write(data)
+
+	return p
+}
+
+// HandlerFunc is an HTTP handler returning an error.
+type HandlerFunc func(rw http.ResponseWriter, r *http.Request) error
+
+// Handler turns the handler into [http.Handler] writing errors it returns as problem details.
+// The handler must not write anything into the response when it returns an error.
+func (w *Writer) Handler(h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if err := h(rw, r); err != nil {
+			w.Write(rw, r, err)
+		}
+	})
+}
+
+// FromResponse returns an error of the failed response, nil is returned for 2xx and 3xx statuses.
+// Problem details are turned into errors with [Problem.AsError], other bodies give errors with
+// [Status] and [errors.Category] specs restored from the status. The body is read but not closed.
+func FromResponse(resp *http.Response) (*errors.Error, error) {
+	if resp.StatusCode < 400 {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read response body")
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == ContentType {
+		p, err := ParseProblem(data)
+		if err != nil {
+			return nil, err
+		}
+		if p.Status == 0 {
+			p.Status = resp.StatusCode
+		}
+
+		return p.AsError(), nil
+	}
+
+	res := errors.New(resp.Status).Int("status", resp.StatusCode)
+	res = errors.Spec(res, Status(resp.StatusCode))
+	res = errors.Spec(res, errors.CategoryOfHTTPStatus(resp.StatusCode))
+	return res, nil
+}
+
+// jsonValue returns the value in the form suitable for JSON encoding.
+func jsonValue(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindGroup:
+		res := map[string]any{}
+		for _, attr := range v.Group() {
+			res[attr.Key] = jsonValue(attr.Value)
+		}
+		return res
+	case slog.KindAny:
+		if x, ok := v.Any().(error); ok {
+			return x.Error()
+		}
+	}
+
+	return v.Any()
+}
+
+// newInstance returns a random URN of UUID v4 form.
+func newInstance(*http.Request) string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+
+	return "urn:uuid:" + string(buf[:])
+}
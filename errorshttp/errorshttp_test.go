@@ -0,0 +1,245 @@
+package errorshttp_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorshttp"
+)
+
+var errUserNotFound = errors.NewSentinel(
+	"user not found",
+	errors.WithSpec(errors.CategoryNotFound),
+	errors.WithSpec(errorshttp.Detail("no such user")),
+)
+
+func ExampleWriter_Write() {
+	w := errorshttp.NewWriter(
+		errorshttp.WithKeys("user_id"),
+		errorshttp.WithInstance(func(r *http.Request) string {
+			return "urn:request:" + r.Header.Get("X-Request-Id")
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/13", nil)
+	req.Header.Set("X-Request-Id", "42")
+	err := errors.Wrap(errUserNotFound, "get user").Int("user_id", 13).Str("table", "users")
+	w.Write(rec, req, err)
+
+	fmt.Println(rec.Code, rec.Header().Get("Content-Type"))
+	fmt.Println(rec.Body.String())
+
+	// Output:
+	// 404 application/problem+json
+	// {"type":"about:blank","title":"Not Found","status":404,"detail":"no such user","instance":"urn:request:42","category":"NotFound","user_id":13}
+}
+
+func ExampleFromResponse() {
+	w := errorshttp.NewWriter(errorshttp.WithKeys("user_id"))
+	srv := httptest.NewServer(w.Handler(func(rw http.ResponseWriter, r *http.Request) error {
+		return errors.Wrap(errUserNotFound, "get user").Int("user_id", 13)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	rerr, err := errorshttp.FromResponse(resp)
+	if err != nil {
+		panic(err)
+	}
+	status, _ := errors.AsSpec[errorshttp.Status](rerr)
+	fmt.Println(rerr.Error())
+	fmt.Println(status, errors.CategoryOf(rerr))
+
+	// Output:
+	// no such user
+	// 404 NotFound
+}
+
+func TestProblem(t *testing.T) {
+	w := errorshttp.NewWriter(errorshttp.WithKeys("a", "status", "detail"))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	type test struct {
+		name   string
+		err    error
+		status int
+		detail string
+		ext    map[string]any
+	}
+	tests := []test{
+		{
+			name:   "unknown",
+			err:    io.EOF,
+			status: http.StatusInternalServerError,
+			ext:    map[string]any{"category": "Unknown"},
+		},
+		{
+			name:   "category",
+			err:    errors.Spec(errors.New("bad input").Int("a", 1), errors.CategoryInvalidArgument),
+			status: http.StatusBadRequest,
+			ext:    map[string]any{"category": "InvalidArgument", "a": int64(1)},
+		},
+		{
+			name:   "status-spec",
+			err:    errors.Spec(errors.New("slow down"), errorshttp.Status(http.StatusTooManyRequests)),
+			status: http.StatusTooManyRequests,
+			ext:    map[string]any{"category": "Unknown"},
+		},
+		{
+			name:   "status-spec-invalid",
+			err:    errors.Spec(errors.Spec(errors.New("odd"), errorshttp.Status(http.StatusOK)), errors.CategoryUnavailable),
+			status: http.StatusServiceUnavailable,
+			ext:    map[string]any{"category": "Unavailable"},
+		},
+		{
+			name:   "outer-wins",
+			err:    errors.Spec(errors.Wrap(errUserNotFound, "lookup").Int("a", 1).Int("status", 200), errorshttp.Detail("gone")).Int("a", 2),
+			status: http.StatusNotFound,
+			detail: "gone",
+			ext:    map[string]any{"category": "NotFound", "a": int64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := w.Problem(req, tt.err)
+			if p.Status != tt.status {
+				t.Errorf("status %d, want %d", p.Status, tt.status)
+			}
+			if p.Title != http.StatusText(tt.status) {
+				t.Errorf("title %q, want %q", p.Title, http.StatusText(tt.status))
+			}
+			if p.Detail != tt.detail {
+				t.Errorf("detail %q, want %q", p.Detail, tt.detail)
+			}
+			if !strings.HasPrefix(p.Instance, "urn:uuid:") || len(p.Instance) != len("urn:uuid:")+36 {
+				t.Errorf("invalid instance %q", p.Instance)
+			}
+			if fmt.Sprint(p.Extensions) != fmt.Sprint(tt.ext) {
+				t.Errorf("extensions %v, want %v", p.Extensions, tt.ext)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	w := errorshttp.NewWriter(errorshttp.WithLogger(logger))
+
+	rec := httptest.NewRecorder()
+	p := w.Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("broken"))
+
+	want := fmt.Sprintf("level=ERROR msg=\"request failed\" err=broken instance=%s status=500\n", p.Instance)
+	if buf.String() != want {
+		t.Errorf("logged %q, want %q", buf.String(), want)
+	}
+}
+
+func TestAsError(t *testing.T) {
+	p, err := errorshttp.ParseProblem([]byte(`{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30,
+		"ratio": 0.6,
+		"blocked": true,
+		"accounts": ["/account/12345", "/account/67890"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := p.AsError()
+	if got, want := e.Error(), "Your current balance is 30, but that costs 50."; got != want {
+		t.Errorf("text %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprint(errors.SLogFlatContext(e)), `[type=https://example.com/probs/out-of-credit instance=/account/12345/msgs/abc accounts=["/account/12345", "/account/67890"] balance=30 blocked=true ratio=0.6 @locations=[]]`; got != want {
+		t.Errorf("context %s, want %s", got, want)
+	}
+	if c := errors.CategoryOf(e); c != errors.CategoryPermissionDenied {
+		t.Errorf("category %s, want %s", c, errors.CategoryPermissionDenied)
+	}
+	if typ, _ := errors.AsSpec[errorshttp.Type](e); typ != "https://example.com/probs/out-of-credit" {
+		t.Errorf("type %q", typ)
+	}
+
+	// Ошибка проходит через следующий сервер с тем же статусом и деталями.
+	next := errorshttp.NewWriter().Problem(httptest.NewRequest(http.MethodGet, "/", nil), errors.Wrap(e, "call billing"))
+	if next.Status != p.Status || next.Detail != p.Detail || next.Type != p.Type {
+		t.Errorf("passed through as %+v", next)
+	}
+}
+
+func TestAsErrorNoStatus(t *testing.T) {
+	p, err := errorshttp.ParseProblem([]byte(`{"title":"boom","detail":"upstream failed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := p.AsError()
+	if errors.IsSpec[errorshttp.Status](e) {
+		t.Error("status spec given for a problem without status")
+	}
+
+	rec := httptest.NewRecorder()
+	next := errorshttp.NewWriter().Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.Wrap(e, "call upstream"))
+	if rec.Code != http.StatusInternalServerError || next.Status != http.StatusInternalServerError {
+		t.Errorf("written with status %d, problem status %d", rec.Code, next.Status)
+	}
+	if next.Detail != "upstream failed" {
+		t.Errorf("detail %q", next.Detail)
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}
+	if err, rerr := errorshttp.FromResponse(ok); err != nil || rerr != nil {
+		t.Errorf("got %v and %v for 200", err, rerr)
+	}
+
+	plain := &http.Response{
+		Status:     "503 Service Unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Content-Type": {"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader("upstream is down")),
+	}
+	err, rerr := errorshttp.FromResponse(plain)
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	if errors.CategoryOf(err) != errors.CategoryUnavailable {
+		t.Errorf("category %s", errors.CategoryOf(err))
+	}
+
+	invalid := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": {errorshttp.ContentType + "; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader("{")),
+	}
+	if _, rerr := errorshttp.FromResponse(invalid); rerr == nil {
+		t.Error("no error for invalid problem details")
+	}
+}
@@ -0,0 +1,230 @@
+package errorshttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/sirkon/errors"
+)
+
+// ContentType is the media type of problem details.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, "about:blank" when empty.
+	Type string
+	// Title is a short summary of the problem type.
+	Title string
+	// Status is the HTTP status code.
+	Status int
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference identifying this occurrence of the problem.
+	Instance string
+	// Extensions are additional members of the problem. Parsed problems have them as [json.RawMessage].
+	Extensions map[string]any
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"type":`)
+	typ := p.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	writeJSON(&buf, typ)
+	if p.Title != "" {
+		buf.WriteString(`,"title":`)
+		writeJSON(&buf, p.Title)
+	}
+	if p.Status != 0 {
+		buf.WriteString(`,"status":`)
+		buf.WriteString(strconv.Itoa(p.Status))
+	}
+	if p.Detail != "" {
+		buf.WriteString(`,"detail":`)
+		writeJSON(&buf, p.Detail)
+	}
+	if p.Instance != "" {
+		buf.WriteString(`,"instance":`)
+		writeJSON(&buf, p.Instance)
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		if !slices.Contains(problemMembers, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		value, err := json.Marshal(p.Extensions[key])
+		if err != nil {
+			return nil, errors.Wrapf(err, "encode extension member %q", key)
+		}
+		buf.WriteByte(',')
+		writeJSON(&buf, key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	var res Problem
+	// RFC 9457 велит игнорировать члены неверного типа, а не отвергать весь документ.
+	_ = json.Unmarshal(members["type"], &res.Type)
+	_ = json.Unmarshal(members["title"], &res.Title)
+	_ = json.Unmarshal(members["status"], &res.Status)
+	_ = json.Unmarshal(members["detail"], &res.Detail)
+	_ = json.Unmarshal(members["instance"], &res.Instance)
+	for key, value := range members {
+		if slices.Contains(problemMembers, key) {
+			continue
+		}
+		if res.Extensions == nil {
+			res.Extensions = map[string]any{}
+		}
+		res.Extensions[key] = value
+	}
+	if res.Type == "" {
+		res.Type = "about:blank"
+	}
+
+	*p = res
+	return nil
+}
+
+// ParseProblem parses a problem details body.
+func ParseProblem(data []byte) (*Problem, error) {
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, errors.Wrap(err, "decode problem details")
+	}
+
+	return &p, nil
+}
+
+// AsError turns the problem into an error for the client side. Its text is the detail
+// of the problem or its title when there is no detail, extension members become context
+// values. The error gets [Status], [Detail], [Type] and [errors.Category] specs restored from
+// the problem, so it can be passed through with the same status and detail. The [Status] spec
+// is given only for 4xx and 5xx statuses, the category decides for problems without them.
+func (p *Problem) AsError() *errors.Error {
+	text := p.Detail
+	if text == "" {
+		text = p.Title
+	}
+	if text == "" {
+		text = "status " + strconv.Itoa(p.Status)
+	}
+
+	res := errors.New(text)
+	if p.Type != "" && p.Type != "about:blank" {
+		res = res.Str("type", p.Type)
+	}
+	if p.Instance != "" {
+		res = res.Str("instance", p.Instance)
+	}
+
+	category := errors.CategoryOfHTTPStatus(p.Status)
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if key == categoryMember {
+			if c, ok := parseCategory(p.Extensions[key]); ok {
+				category = c
+				continue
+			}
+		}
+		res = appendExtension(res, key, p.Extensions[key])
+	}
+
+	if Status(p.Status).valid() {
+		res = errors.Spec(res, Status(p.Status))
+	}
+	res = errors.Spec(res, category)
+	if p.Detail != "" {
+		res = errors.Spec(res, Detail(p.Detail))
+	}
+	if p.Type != "" && p.Type != "about:blank" {
+		res = errors.Spec(res, Type(p.Type))
+	}
+
+	return res
+}
+
+// appendExtension adds extension member to the error as a context value of the matching type.
+func appendExtension(err *errors.Error, key string, value any) *errors.Error {
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		return err.Any(key, value)
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if dec.Decode(&v) != nil {
+		return err.Any(key, raw)
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return err.Bool(key, v)
+	case string:
+		return err.Str(key, v)
+	case json.Number:
+		if i, ierr := v.Int64(); ierr == nil {
+			return err.I64(key, i)
+		}
+		if f, ferr := v.Float64(); ferr == nil {
+			return err.F64(key, f)
+		}
+	}
+
+	return err.Any(key, raw)
+}
+
+func parseCategory(value any) (errors.Category, bool) {
+	var name string
+	switch v := value.(type) {
+	case json.RawMessage:
+		if json.Unmarshal(v, &name) != nil {
+			return 0, false
+		}
+	case string:
+		name = v
+	case errors.Category:
+		return v, true
+	default:
+		return 0, false
+	}
+
+	for c := errors.CategoryOK; c <= errors.CategoryUnauthenticated; c++ {
+		if c.String() == name {
+			return c, true
+		}
+	}
+
+	return 0, false
+}
+
+func writeJSON(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
@@ -0,0 +1,767 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: errors.proto
+
+package errorspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Layer_Kind int32
+
+const (
+	Layer_KIND_UNSPECIFIED Layer_Kind = 0
+	// KIND_NEW is a layer created with errors.New.
+	Layer_KIND_NEW Layer_Kind = 1
+	// KIND_WRAP is a layer created with errors.Wrap.
+	Layer_KIND_WRAP Layer_Kind = 2
+	// KIND_JUST is a layer created with errors.Just.
+	Layer_KIND_JUST Layer_Kind = 3
+	// KIND_CAUSE is a foreign error without a layer of its own.
+	Layer_KIND_CAUSE Layer_Kind = 4
+)
+
+// Enum value maps for Layer_Kind.
+var (
+	Layer_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_NEW",
+		2: "KIND_WRAP",
+		3: "KIND_JUST",
+		4: "KIND_CAUSE",
+	}
+	Layer_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_NEW":         1,
+		"KIND_WRAP":        2,
+		"KIND_JUST":        3,
+		"KIND_CAUSE":       4,
+	}
+)
+
+func (x Layer_Kind) Enum() *Layer_Kind {
+	p := new(Layer_Kind)
+	*p = x
+	return p
+}
+
+func (x Layer_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Layer_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_errors_proto_enumTypes[0].Descriptor()
+}
+
+func (Layer_Kind) Type() protoreflect.EnumType {
+	return &file_errors_proto_enumTypes[0]
+}
+
+func (x Layer_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Layer_Kind.Descriptor instead.
+func (Layer_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{1, 0}
+}
+
+// Error is a structured error with its layers going from the innermost to the outermost.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Layers []*Layer `protobuf:"bytes,1,rep,name=layers,proto3" json:"layers,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Error) GetLayers() []*Layer {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+// Layer is a single layer of an error.
+type Layer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind Layer_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=sirkon.errors.Layer_Kind" json:"kind,omitempty"`
+	// message is a text of the layer, it is empty for Just layers.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// cause is set for layers over foreign errors.
+	Cause *Cause `protobuf:"bytes,3,opt,name=cause,proto3" json:"cause,omitempty"`
+	// location is a file:line location of the layer if it was recorded.
+	Location string `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	// service is a name of the service the layer was created in.
+	Service string  `protobuf:"bytes,5,opt,name=service,proto3" json:"service,omitempty"`
+	Attrs   []*Attr `protobuf:"bytes,6,rep,name=attrs,proto3" json:"attrs,omitempty"`
+}
+
+func (x *Layer) Reset() {
+	*x = Layer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Layer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Layer) ProtoMessage() {}
+
+func (x *Layer) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Layer.ProtoReflect.Descriptor instead.
+func (*Layer) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Layer) GetKind() Layer_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Layer_KIND_UNSPECIFIED
+}
+
+func (x *Layer) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Layer) GetCause() *Cause {
+	if x != nil {
+		return x.Cause
+	}
+	return nil
+}
+
+func (x *Layer) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Layer) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Layer) GetAttrs() []*Attr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+// Cause is a foreign error wrapped by a layer.
+type Cause struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// text is a text of the foreign error.
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// layers are layers of a structured error found in the chain of the foreign error.
+	Layers []*Layer `protobuf:"bytes,2,rep,name=layers,proto3" json:"layers,omitempty"`
+	// sentinel is an identifier of the registered sentinel in the chain of the foreign error.
+	Sentinel string `protobuf:"bytes,3,opt,name=sentinel,proto3" json:"sentinel,omitempty"`
+}
+
+func (x *Cause) Reset() {
+	*x = Cause{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Cause) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cause) ProtoMessage() {}
+
+func (x *Cause) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cause.ProtoReflect.Descriptor instead.
+func (*Cause) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Cause) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Cause) GetLayers() []*Layer {
+	if x != nil {
+		return x.Layers
+	}
+	return nil
+}
+
+func (x *Cause) GetSentinel() string {
+	if x != nil {
+		return x.Sentinel
+	}
+	return ""
+}
+
+// Attr is a context value of a layer.
+type Attr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Types that are assignable to Value:
+	//	*Attr_Bool
+	//	*Attr_Int64
+	//	*Attr_Uint64
+	//	*Attr_Double
+	//	*Attr_String_
+	//	*Attr_Bytes
+	//	*Attr_Duration
+	//	*Attr_Time
+	//	*Attr_Error
+	//	*Attr_Group
+	//	*Attr_Json
+	Value isAttr_Value `protobuf_oneof:"value"`
+}
+
+func (x *Attr) Reset() {
+	*x = Attr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Attr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attr) ProtoMessage() {}
+
+func (x *Attr) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attr.ProtoReflect.Descriptor instead.
+func (*Attr) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Attr) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (m *Attr) GetValue() isAttr_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Attr) GetBool() bool {
+	if x, ok := x.GetValue().(*Attr_Bool); ok {
+		return x.Bool
+	}
+	return false
+}
+
+func (x *Attr) GetInt64() int64 {
+	if x, ok := x.GetValue().(*Attr_Int64); ok {
+		return x.Int64
+	}
+	return 0
+}
+
+func (x *Attr) GetUint64() uint64 {
+	if x, ok := x.GetValue().(*Attr_Uint64); ok {
+		return x.Uint64
+	}
+	return 0
+}
+
+func (x *Attr) GetDouble() float64 {
+	if x, ok := x.GetValue().(*Attr_Double); ok {
+		return x.Double
+	}
+	return 0
+}
+
+func (x *Attr) GetString_() string {
+	if x, ok := x.GetValue().(*Attr_String_); ok {
+		return x.String_
+	}
+	return ""
+}
+
+func (x *Attr) GetBytes() []byte {
+	if x, ok := x.GetValue().(*Attr_Bytes); ok {
+		return x.Bytes
+	}
+	return nil
+}
+
+func (x *Attr) GetDuration() *durationpb.Duration {
+	if x, ok := x.GetValue().(*Attr_Duration); ok {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *Attr) GetTime() *timestamppb.Timestamp {
+	if x, ok := x.GetValue().(*Attr_Time); ok {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Attr) GetError() string {
+	if x, ok := x.GetValue().(*Attr_Error); ok {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Attr) GetGroup() *Group {
+	if x, ok := x.GetValue().(*Attr_Group); ok {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *Attr) GetJson() []byte {
+	if x, ok := x.GetValue().(*Attr_Json); ok {
+		return x.Json
+	}
+	return nil
+}
+
+type isAttr_Value interface {
+	isAttr_Value()
+}
+
+type Attr_Bool struct {
+	Bool bool `protobuf:"varint,2,opt,name=bool,proto3,oneof"`
+}
+
+type Attr_Int64 struct {
+	Int64 int64 `protobuf:"varint,3,opt,name=int64,proto3,oneof"`
+}
+
+type Attr_Uint64 struct {
+	Uint64 uint64 `protobuf:"varint,4,opt,name=uint64,proto3,oneof"`
+}
+
+type Attr_Double struct {
+	Double float64 `protobuf:"fixed64,5,opt,name=double,proto3,oneof"`
+}
+
+type Attr_String_ struct {
+	String_ string `protobuf:"bytes,6,opt,name=string,proto3,oneof"`
+}
+
+type Attr_Bytes struct {
+	Bytes []byte `protobuf:"bytes,7,opt,name=bytes,proto3,oneof"`
+}
+
+type Attr_Duration struct {
+	Duration *durationpb.Duration `protobuf:"bytes,8,opt,name=duration,proto3,oneof"`
+}
+
+type Attr_Time struct {
+	Time *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=time,proto3,oneof"`
+}
+
+type Attr_Error struct {
+	// error is a text of an error value.
+	Error string `protobuf:"bytes,10,opt,name=error,proto3,oneof"`
+}
+
+type Attr_Group struct {
+	Group *Group `protobuf:"bytes,11,opt,name=group,proto3,oneof"`
+}
+
+type Attr_Json struct {
+	// json is a JSON encoding of a value of any other type.
+	Json []byte `protobuf:"bytes,12,opt,name=json,proto3,oneof"`
+}
+
+func (*Attr_Bool) isAttr_Value() {}
+
+func (*Attr_Int64) isAttr_Value() {}
+
+func (*Attr_Uint64) isAttr_Value() {}
+
+func (*Attr_Double) isAttr_Value() {}
+
+func (*Attr_String_) isAttr_Value() {}
+
+func (*Attr_Bytes) isAttr_Value() {}
+
+func (*Attr_Duration) isAttr_Value() {}
+
+func (*Attr_Time) isAttr_Value() {}
+
+func (*Attr_Error) isAttr_Value() {}
+
+func (*Attr_Group) isAttr_Value() {}
+
+func (*Attr_Json) isAttr_Value() {}
+
+// Group is a group of context values.
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Attrs []*Attr `protobuf:"bytes,1,rep,name=attrs,proto3" json:"attrs,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_errors_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_errors_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_errors_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Group) GetAttrs() []*Attr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+var File_errors_proto protoreflect.FileDescriptor
+
+var file_errors_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d,
+	0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x1a, 0x1e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x35,
+	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2c, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e,
+	0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x52, 0x06, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x73, 0x22, 0xb7, 0x02, 0x0a, 0x05, 0x4c, 0x61, 0x79, 0x65, 0x72, 0x12,
+	0x2d, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e,
+	0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x4c, 0x61,
+	0x79, 0x65, 0x72, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a, 0x0a, 0x05, 0x63, 0x61, 0x75, 0x73,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e,
+	0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x43, 0x61, 0x75, 0x73, 0x65, 0x52, 0x05, 0x63,
+	0x61, 0x75, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x61, 0x74,
+	0x74, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x69, 0x72, 0x6b,
+	0x6f, 0x6e, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x52, 0x05,
+	0x61, 0x74, 0x74, 0x72, 0x73, 0x22, 0x58, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a,
+	0x10, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4e, 0x45, 0x57, 0x10,
+	0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x57, 0x52, 0x41, 0x50, 0x10, 0x02,
+	0x12, 0x0d, 0x0a, 0x09, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4a, 0x55, 0x53, 0x54, 0x10, 0x03, 0x12,
+	0x0e, 0x0a, 0x0a, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x41, 0x55, 0x53, 0x45, 0x10, 0x04, 0x22,
+	0x65, 0x0a, 0x05, 0x43, 0x61, 0x75, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x2c, 0x0a, 0x06,
+	0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73,
+	0x69, 0x72, 0x6b, 0x6f, 0x6e, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x4c, 0x61, 0x79,
+	0x65, 0x72, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65,
+	0x6e, 0x74, 0x69, 0x6e, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65,
+	0x6e, 0x74, 0x69, 0x6e, 0x65, 0x6c, 0x22, 0xfc, 0x02, 0x0a, 0x04, 0x41, 0x74, 0x74, 0x72, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x04, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x00, 0x52, 0x04, 0x62, 0x6f, 0x6f, 0x6c, 0x12, 0x16, 0x0a, 0x05, 0x69, 0x6e, 0x74, 0x36, 0x34,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x12,
+	0x18, 0x0a, 0x06, 0x75, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x48,
+	0x00, 0x52, 0x06, 0x75, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x12, 0x18, 0x0a, 0x06, 0x64, 0x6f, 0x75,
+	0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x06, 0x64, 0x6f, 0x75,
+	0x62, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a,
+	0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x48, 0x00, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30,
+	0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x00, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x12, 0x16, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2c, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e,
+	0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x48, 0x00, 0x52,
+	0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x14, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x42, 0x07, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x32, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x29,
+	0x0a, 0x05, 0x61, 0x74, 0x74, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x2e, 0x41, 0x74,
+	0x74, 0x72, 0x52, 0x05, 0x61, 0x74, 0x74, 0x72, 0x73, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x69, 0x72, 0x6b, 0x6f, 0x6e, 0x2f, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x73, 0x2f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_errors_proto_rawDescOnce sync.Once
+	file_errors_proto_rawDescData = file_errors_proto_rawDesc
+)
+
+func file_errors_proto_rawDescGZIP() []byte {
+	file_errors_proto_rawDescOnce.Do(func() {
+		file_errors_proto_rawDescData = protoimpl.X.CompressGZIP(file_errors_proto_rawDescData)
+	})
+	return file_errors_proto_rawDescData
+}
+
+var file_errors_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_errors_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_errors_proto_goTypes = []interface{}{
+	(Layer_Kind)(0),               // 0: sirkon.errors.Layer.Kind
+	(*Error)(nil),                 // 1: sirkon.errors.Error
+	(*Layer)(nil),                 // 2: sirkon.errors.Layer
+	(*Cause)(nil),                 // 3: sirkon.errors.Cause
+	(*Attr)(nil),                  // 4: sirkon.errors.Attr
+	(*Group)(nil),                 // 5: sirkon.errors.Group
+	(*durationpb.Duration)(nil),   // 6: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_errors_proto_depIdxs = []int32{
+	2, // 0: sirkon.errors.Error.layers:type_name -> sirkon.errors.Layer
+	0, // 1: sirkon.errors.Layer.kind:type_name -> sirkon.errors.Layer.Kind
+	3, // 2: sirkon.errors.Layer.cause:type_name -> sirkon.errors.Cause
+	4, // 3: sirkon.errors.Layer.attrs:type_name -> sirkon.errors.Attr
+	2, // 4: sirkon.errors.Cause.layers:type_name -> sirkon.errors.Layer
+	6, // 5: sirkon.errors.Attr.duration:type_name -> google.protobuf.Duration
+	7, // 6: sirkon.errors.Attr.time:type_name -> google.protobuf.Timestamp
+	5, // 7: sirkon.errors.Attr.group:type_name -> sirkon.errors.Group
+	4, // 8: sirkon.errors.Group.attrs:type_name -> sirkon.errors.Attr
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_errors_proto_init() }
+func file_errors_proto_init() {
+	if File_errors_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_errors_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_errors_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Layer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_errors_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Cause); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_errors_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Attr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_errors_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_errors_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*Attr_Bool)(nil),
+		(*Attr_Int64)(nil),
+		(*Attr_Uint64)(nil),
+		(*Attr_Double)(nil),
+		(*Attr_String_)(nil),
+		(*Attr_Bytes)(nil),
+		(*Attr_Duration)(nil),
+		(*Attr_Time)(nil),
+		(*Attr_Error)(nil),
+		(*Attr_Group)(nil),
+		(*Attr_Json)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_errors_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_errors_proto_goTypes,
+		DependencyIndexes: file_errors_proto_depIdxs,
+		EnumInfos:         file_errors_proto_enumTypes,
+		MessageInfos:      file_errors_proto_msgTypes,
+	}.Build()
+	File_errors_proto = out.File
+	file_errors_proto_rawDesc = nil
+	file_errors_proto_goTypes = nil
+	file_errors_proto_depIdxs = nil
+}
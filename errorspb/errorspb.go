@@ -0,0 +1,234 @@
+// Package errorspb provides a protobuf wire format for structured errors.
+//
+// It keeps everything [errors.Error.MarshalJSON] does: layer kinds, messages, locations,
+// typed context values and texts of foreign causes.
+package errorspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative errors.proto
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sirkon/errors"
+)
+
+// New converts the error into a protobuf message. A nil error gives a nil message,
+// which is encoded as an empty one.
+func New(err *errors.Error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{
+		Layers: newLayers(err.Snapshot().Layers),
+	}
+}
+
+// AsError rebuilds the error from the message. Layers of the result are marked as
+// remote ones, see [errors.FromSnapshot].
+func (x *Error) AsError() (*errors.Error, error) {
+	layers, err := snapshotLayers(x.GetLayers())
+	if err != nil {
+		return nil, err
+	}
+
+	return errors.FromSnapshot(&errors.Snapshot{Layers: layers})
+}
+
+var layerKinds = map[errors.LayerKind]Layer_Kind{
+	errors.LayerNew:   Layer_KIND_NEW,
+	errors.LayerWrap:  Layer_KIND_WRAP,
+	errors.LayerJust:  Layer_KIND_JUST,
+	errors.LayerCause: Layer_KIND_CAUSE,
+}
+
+func newLayers(layers []errors.SnapshotLayer) []*Layer {
+	if len(layers) == 0 {
+		return nil
+	}
+
+	res := make([]*Layer, len(layers))
+	for i, layer := range layers {
+		res[i] = &Layer{
+			Kind:     layerKinds[layer.Kind],
+			Message:  layer.Message,
+			Location: layer.Location,
+			Service:  layer.Service,
+			Attrs:    newAttrs(layer.Attrs),
+		}
+		if layer.Cause != nil {
+			res[i].Cause = &Cause{
+				Text:     layer.Cause.Text,
+				Layers:   newLayers(layer.Cause.Layers),
+				Sentinel: layer.Cause.Sentinel,
+			}
+		}
+	}
+
+	return res
+}
+
+func newAttrs(attrs []slog.Attr) []*Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	res := make([]*Attr, len(attrs))
+	for i, attr := range attrs {
+		res[i] = newAttr(attr)
+	}
+
+	return res
+}
+
+func newAttr(attr slog.Attr) *Attr {
+	res := &Attr{Key: attr.Key}
+
+	v := attr.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindBool:
+		res.Value = &Attr_Bool{Bool: v.Bool()}
+	case slog.KindInt64:
+		res.Value = &Attr_Int64{Int64: v.Int64()}
+	case slog.KindUint64:
+		res.Value = &Attr_Uint64{Uint64: v.Uint64()}
+	case slog.KindFloat64:
+		res.Value = &Attr_Double{Double: v.Float64()}
+	case slog.KindString:
+		res.Value = &Attr_String_{String_: v.String()}
+	case slog.KindDuration:
+		res.Value = &Attr_Duration{Duration: durationpb.New(v.Duration())}
+	case slog.KindTime:
+		res.Value = &Attr_Time{Time: timestamppb.New(v.Time())}
+	case slog.KindGroup:
+		res.Value = &Attr_Group{Group: &Group{Attrs: newAttrs(v.Group())}}
+	default:
+		switch x := v.Any().(type) {
+		case []byte:
+			res.Value = &Attr_Bytes{Bytes: x}
+		case error:
+			res.Value = &Attr_Error{Error: x.Error()}
+		case json.RawMessage:
+			res.Value = &Attr_Json{Json: x}
+		default:
+			// Снимок ошибки уже приводит значения к поддерживаемым типам, это лишь страховка.
+			data, err := json.Marshal(x)
+			if err != nil {
+				res.Value = &Attr_String_{String_: fmt.Sprint(x)}
+				break
+			}
+			res.Value = &Attr_Json{Json: data}
+		}
+	}
+
+	return res
+}
+
+func snapshotLayers(layers []*Layer) ([]errors.SnapshotLayer, error) {
+	res := make([]errors.SnapshotLayer, len(layers))
+	for i, layer := range layers {
+		var kind errors.LayerKind
+		switch layer.GetKind() {
+		case Layer_KIND_NEW:
+			kind = errors.LayerNew
+		case Layer_KIND_WRAP:
+			kind = errors.LayerWrap
+		case Layer_KIND_JUST:
+			kind = errors.LayerJust
+		case Layer_KIND_CAUSE:
+			kind = errors.LayerCause
+		default:
+			return nil, errors.Newf("invalid kind %s of layer %d", layer.GetKind(), i)
+		}
+
+		attrs, err := snapshotAttrs(layer.GetAttrs())
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode attributes of layer %d", i)
+		}
+
+		res[i] = errors.SnapshotLayer{
+			Kind:     kind,
+			Message:  layer.GetMessage(),
+			Location: layer.GetLocation(),
+			Service:  layer.GetService(),
+			Attrs:    attrs,
+		}
+		if layer.GetCause() == nil {
+			continue
+		}
+
+		causeLayers, err := snapshotLayers(layer.GetCause().GetLayers())
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode cause of layer %d", i)
+		}
+		res[i].Cause = &errors.SnapshotCause{
+			Text:     layer.GetCause().GetText(),
+			Sentinel: layer.GetCause().GetSentinel(),
+			Layers:   causeLayers,
+		}
+	}
+
+	return res, nil
+}
+
+func snapshotAttrs(attrs []*Attr) ([]slog.Attr, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	res := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		v, err := snapshotValue(attr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode value of %q", attr.GetKey())
+		}
+		res[i] = slog.Attr{Key: attr.GetKey(), Value: v}
+	}
+
+	return res, nil
+}
+
+func snapshotValue(attr *Attr) (slog.Value, error) {
+	switch v := attr.GetValue().(type) {
+	case *Attr_Bool:
+		return slog.BoolValue(v.Bool), nil
+	case *Attr_Int64:
+		return slog.Int64Value(v.Int64), nil
+	case *Attr_Uint64:
+		return slog.Uint64Value(v.Uint64), nil
+	case *Attr_Double:
+		return slog.Float64Value(v.Double), nil
+	case *Attr_String_:
+		return slog.StringValue(v.String_), nil
+	case *Attr_Bytes:
+		return slog.AnyValue(v.Bytes), nil
+	case *Attr_Duration:
+		if err := v.Duration.CheckValid(); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.DurationValue(v.Duration.AsDuration()), nil
+	case *Attr_Time:
+		if err := v.Time.CheckValid(); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.TimeValue(v.Time.AsTime()), nil
+	case *Attr_Error:
+		return slog.AnyValue(stderrors.New(v.Error)), nil
+	case *Attr_Group:
+		attrs, err := snapshotAttrs(v.Group.GetAttrs())
+		return slog.GroupValue(attrs...), err
+	case *Attr_Json:
+		if !json.Valid(v.Json) {
+			return slog.Value{}, errors.New("invalid JSON")
+		}
+		return slog.AnyValue(json.RawMessage(v.Json)), nil
+	default:
+		return slog.Value{}, errors.New("missing value")
+	}
+}
@@ -0,0 +1,145 @@
+package errorspb_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorspb"
+)
+
+func TestRoundTrip(t *testing.T) {
+	errors.SetServiceName("billing")
+	defer errors.SetServiceName("")
+
+	errs := map[string]*errors.Error{}
+	errs["kinds"] = errors.New("kinds").
+		Bool("bool", true).
+		Int("int", -1).
+		U64("uint", math.MaxUint64).
+		F64("nan", math.NaN()).
+		Str("str", "text").
+		Bytes("bytes", []byte{0, 1, 2}).
+		Strs("strs", []string{"a", "b"}).
+		Any("duration", time.Second).
+		Any("time", time.Date(2024, 3, 1, 12, 0, 0, 5, time.UTC)).
+		Any("error", io.EOF).
+		Any("group", slog.GroupValue(slog.Int("a", 1), slog.Group("b", slog.String("c", "d"))))
+	errs["layers"] = errors.Just(errors.Wrap(errors.New("inner").Int("code", 1), "middle")).F64("e", math.E)
+	errs["foreign"] = errors.Wrap(fmt.Errorf("foreign: %w", errors.New("deep").Str("k", "v")), "outer").Int("n", 1)
+	errs["foreign-just"] = errors.Just(io.EOF).Int("n", 2)
+	errs["phantom"] = errors.Spec(fmt.Errorf("foreign: %w", errors.New("deep")), new(1))
+
+	errors.InsertLocations()
+	errs["locations"] = errors.Wrap(errors.New("located").Int("n", 5), "wrap")
+	errors.DoNotInsertLocations()
+
+	for name, err := range errs {
+		data, merr := proto.Marshal(errorspb.New(err))
+		if merr != nil {
+			t.Errorf("%s: marshal error: %v", name, merr)
+			continue
+		}
+
+		var msg errorspb.Error
+		if uerr := proto.Unmarshal(data, &msg); uerr != nil {
+			t.Errorf("%s: unmarshal error: %v", name, uerr)
+			continue
+		}
+		decoded, derr := msg.AsError()
+		if derr != nil {
+			t.Errorf("%s: decode error: %v", name, derr)
+			continue
+		}
+
+		if got, want := decoded.Error(), err.Error(); got != want {
+			t.Errorf("%s: text %q, want %q", name, got, want)
+		}
+
+		// JSON encoding keeps kinds, services and locations as well.
+		got, _ := json.Marshal(decoded)
+		want, _ := json.Marshal(err)
+		if string(got) != string(want) {
+			t.Errorf("%s: decoded\n%s\nwant\n%s", name, got, want)
+		}
+	}
+}
+
+func TestErrorValue(t *testing.T) {
+	err := errors.New("error value").Any("cause", io.ErrUnexpectedEOF)
+
+	fromPB, perr := errorspb.New(err).AsError()
+	if perr != nil {
+		t.Fatal(perr)
+	}
+	data, _ := json.Marshal(err)
+	fromJSON, jerr := errors.Decode(data)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	// Ошибки в значениях должны восстанавливаться одинаково при любом формате.
+	got := errors.SLogFlatContext(fromPB)[0].Value.Any()
+	want := errors.SLogFlatContext(fromJSON)[0].Value.Any()
+	if fmt.Sprintf("%T %v", got, got) != fmt.Sprintf("%T %v", want, want) {
+		t.Errorf("error value %T %v, want %T %v", got, got, want, want)
+	}
+}
+
+var errNotFound = errors.NewSentinel("not found", errors.SentinelID("errorspb-test.not-found"))
+
+func TestSentinel(t *testing.T) {
+	data, err := proto.Marshal(errorspb.New(errors.Wrap(errNotFound, "get user")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg errorspb.Error
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := msg.AsError()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !errors.Is(decoded, errNotFound) {
+		t.Errorf("%v does not match the sentinel", decoded)
+	}
+}
+
+func TestNil(t *testing.T) {
+	data, err := proto.Marshal(errorspb.New(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg errorspb.Error
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := msg.AsError(); err == nil {
+		t.Error("no error for the message of a nil error")
+	}
+}
+
+func TestAsErrorInvalid(t *testing.T) {
+	for name, msg := range map[string]*errorspb.Error{
+		"empty":        {},
+		"kind":         {Layers: []*errorspb.Layer{{}}},
+		"cause":        {Layers: []*errorspb.Layer{{Kind: errorspb.Layer_KIND_CAUSE}}},
+		"value":        {Layers: []*errorspb.Layer{{Kind: errorspb.Layer_KIND_NEW, Attrs: []*errorspb.Attr{{Key: "k"}}}}},
+		"invalid-json": {Layers: []*errorspb.Layer{{Kind: errorspb.Layer_KIND_NEW, Attrs: []*errorspb.Attr{{Key: "k", Value: &errorspb.Attr_Json{Json: []byte("{")}}}}}},
+	} {
+		if _, err := msg.AsError(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
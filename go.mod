@@ -2,9 +2,4 @@ module github.com/sirkon/errors
 
 go 1.26
 
-require (
-	github.com/golang/protobuf v1.5.2 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/sirkon/deepequal v0.5.9 // indirect
-	google.golang.org/protobuf v1.27.1 // indirect
-)
+require google.golang.org/protobuf v1.27.1
@@ -34,6 +34,8 @@ func main() {
 			},
 			true,
 			-1,
+			errorsctx.WithLocationLinks(errorsctx.LocationLinkVSCode),
+			errorsctx.WithSourceSnippets(1),
 		),
 	)
 
@@ -0,0 +1,37 @@
+// Package location handles file:line locations of errors.
+package location
+
+import (
+	"strings"
+)
+
+// Split splits file:line location into the file and the line number.
+func Split(loc string) (file, line string, ok bool) {
+	idx := strings.LastIndexByte(loc, ':')
+	if idx <= 0 || idx == len(loc)-1 {
+		return "", "", false
+	}
+
+	line = loc[idx+1:]
+	for i := range len(line) {
+		if line[i] < '0' || line[i] > '9' {
+			return "", "", false
+		}
+	}
+
+	return loc[:idx], line, true
+}
+
+// FunctionPackage extracts package path from the fully qualified function name:
+// github.com/user/project/pkg.(*Type).Method → github.com/user/project/pkg.
+// Functions without a package, such as "panic", give an empty string. Dots escaped
+// in the last path element by the runtime are restored.
+func FunctionPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+
+	return strings.ReplaceAll(fn[:slash+1+dot], "%2e", ".")
+}
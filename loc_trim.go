@@ -0,0 +1,317 @@
+package errors
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/sirkon/errors/internal/location"
+)
+
+// LocationTrimmer rewrites the file path of recorded locations before they are rendered.
+type LocationTrimmer func(file string) string
+
+var locationTrim atomic.Pointer[locationTrimState]
+
+// TrimLocations sets the strategy to shorten file paths of locations inserted with [InsertLocations].
+// Locations are still recorded with absolute paths and trimming applies when they are rendered
+// by [SLogTreeContext], [SLogFlatContext] and [ErrorContextDeliverer]. Passing nil disables trimming,
+// this is the default mode.
+//
+// Available strategies are [TrimModuleRoot], [TrimModulePath] and [TrimPrefixes].
+func TrimLocations(trimmer LocationTrimmer) {
+	if trimmer == nil {
+		locationTrim.Store(nil)
+		return
+	}
+
+	locationTrim.Store(&locationTrimState{
+		trim: trimmer,
+	})
+}
+
+// OriginalLocation returns the untrimmed file:line location for the one trimmed with the current
+// [LocationTrimmer]. Locations which were not trimmed are returned as is.
+//
+// It is meant for renderers which need an access to the source file itself.
+func OriginalLocation(loc string) string {
+	state := locationTrim.Load()
+	if state == nil {
+		return loc
+	}
+
+	file, line, ok := location.Split(loc)
+	if !ok {
+		return loc
+	}
+
+	orig, ok := state.origins.Load(file)
+	if !ok {
+		return loc
+	}
+
+	return orig.(string) + ":" + line
+}
+
+// TrimModuleRoot trims file paths of the main module relative to its root, so
+// /home/user/src/project/pkg/x.go becomes pkg/x.go. Files of other modules are
+// trimmed like [TrimModulePath] does to avoid ambiguity.
+//
+// Modules are taken from [debug.ReadBuildInfo], nothing is read from the filesystem,
+// so it works for deployed binaries as well. The root of the main module is found by
+// its functions on the call stack, thus TrimModuleRoot must be called from the main
+// module, in its main or init functions for instance. Paths not belonging to any
+// module of the binary are left as is.
+func TrimModuleRoot() LocationTrimmer {
+	modules := readBuildModules()
+	return func(file string) string {
+		module, version, rel, ok := modules.locate(file)
+		if !ok {
+			return file
+		}
+
+		if module == modules.main {
+			return rel
+		}
+
+		return moduleFilePath(module, version, rel)
+	}
+}
+
+// TrimModulePath trims file paths the way -trimpath build flag does:
+// /home/user/src/project/pkg/x.go becomes github.com/user/project/pkg/x.go,
+// and files from the module cache keep the version: github.com/user/dep@v1.2.3/x.go.
+//
+// Modules are found the same way [TrimModuleRoot] does.
+func TrimModulePath() LocationTrimmer {
+	modules := readBuildModules()
+	return func(file string) string {
+		module, version, rel, ok := modules.locate(file)
+		if !ok {
+			return file
+		}
+
+		return moduleFilePath(module, version, rel)
+	}
+}
+
+// TrimPrefixes removes the first matching prefix from file paths. Paths without
+// any of these prefixes are left as is.
+func TrimPrefixes(prefixes ...string) LocationTrimmer {
+	prefixes = append([]string(nil), prefixes...)
+	return func(file string) string {
+		for _, prefix := range prefixes {
+			if prefix == "" {
+				continue
+			}
+
+			if rest, ok := strings.CutPrefix(file, prefix); ok {
+				return strings.TrimLeft(rest, `/\`)
+			}
+		}
+
+		return file
+	}
+}
+
+// trimLocation applies the current trimming strategy to the given file:line location.
+func trimLocation(loc string) string {
+	state := locationTrim.Load()
+	if state == nil {
+		return loc
+	}
+
+	file, line, ok := location.Split(loc)
+	if !ok {
+		return loc
+	}
+
+	if trimmed, ok := state.trimmed.Load(file); ok {
+		return trimmed.(string) + ":" + line
+	}
+
+	trimmed := state.trim(file)
+	state.trimmed.Store(file, trimmed)
+	if trimmed != file {
+		state.origins.Store(trimmed, file)
+	}
+
+	return trimmed + ":" + line
+}
+
+type locationTrimState struct {
+	trim    LocationTrimmer
+	trimmed sync.Map
+	origins sync.Map
+}
+
+// moduleFilePath builds module@version/path/file.go path, the version is omitted when empty.
+func moduleFilePath(module, version, rel string) string {
+	if version != "" {
+		module += "@" + version
+	}
+
+	return module + "/" + rel
+}
+
+// buildModules are modules of the binary known from its build info.
+type buildModules struct {
+	main     string
+	mainRoot string
+	deps     []buildModule
+}
+
+type buildModule struct {
+	path    string
+	version string
+	// cached is the path@version part of paths in the module cache.
+	cached string
+	// dir is the directory of modules replaced with local ones.
+	dir string
+}
+
+func readBuildModules() *buildModules {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return &buildModules{}
+	}
+
+	res := &buildModules{
+		main:     info.Main.Path,
+		mainRoot: mainModuleRoot(info),
+	}
+	for _, dep := range info.Deps {
+		m := buildModule{
+			path:    dep.Path,
+			version: dep.Version,
+		}
+		if r := dep.Replace; r != nil {
+			if r.Version == "" {
+				// Локальная замена, файлы лежат в каталоге замены.
+				m.version = ""
+				m.dir = filepath.ToSlash(r.Path)
+			} else {
+				m.version = r.Version
+				m.cached = escapeModulePath(r.Path) + "@" + escapeModulePath(r.Version)
+			}
+		} else {
+			m.cached = escapeModulePath(dep.Path) + "@" + escapeModulePath(dep.Version)
+		}
+		res.deps = append(res.deps, m)
+	}
+
+	return res
+}
+
+// locate returns the module the file belongs to and the path of the file relative to the module root.
+func (m *buildModules) locate(file string) (module, version, rel string, ok bool) {
+	file = filepath.ToSlash(file)
+
+	if m.mainRoot != "" {
+		if rest, ok := strings.CutPrefix(file, m.mainRoot+"/"); ok {
+			if vendored, ok := strings.CutPrefix(rest, "vendor/"); ok {
+				if dep, rel, ok := m.vendored(vendored); ok {
+					return dep.path, "", rel, true
+				}
+			}
+
+			return m.main, "", rest, true
+		}
+	}
+
+	// Пути бинарей, собранных с -trimpath, уже начинаются с пути модуля.
+	if m.main != "" {
+		if rest, ok := strings.CutPrefix(file, m.main+"/"); ok {
+			return m.main, "", rest, true
+		}
+	}
+
+	for _, dep := range m.deps {
+		if dep.dir != "" {
+			if rest, ok := strings.CutPrefix(file, dep.dir+"/"); ok {
+				return dep.path, "", rest, true
+			}
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(file, dep.path+"@"+dep.version+"/"); ok {
+			return dep.path, dep.version, rest, true
+		}
+		if _, rest, ok := strings.Cut(file, "/"+dep.cached+"/"); ok {
+			return dep.path, dep.version, rest, true
+		}
+	}
+
+	return "", "", "", false
+}
+
+// vendored finds the dependency of the file from the vendor directory.
+func (m *buildModules) vendored(file string) (dep buildModule, rel string, ok bool) {
+	for _, d := range m.deps {
+		rest, found := strings.CutPrefix(file, d.path+"/")
+		if found && len(d.path) > len(dep.path) {
+			dep, rel, ok = d, rest, true
+		}
+	}
+
+	return dep, rel, ok
+}
+
+// mainModuleRoot finds the root of the main module by files of its functions on the call stack.
+func mainModuleRoot(info *debug.BuildInfo) string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for {
+		frame, more := frames.Next()
+		if root, ok := frameModuleRoot(info, frame); ok {
+			return root
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func frameModuleRoot(info *debug.BuildInfo, frame runtime.Frame) (string, bool) {
+	pkg := location.FunctionPackage(frame.Function)
+	if pkg == "main" {
+		pkg = info.Path
+	}
+	pkg = strings.TrimSuffix(pkg, "_test")
+
+	rel, ok := strings.CutPrefix(pkg, info.Main.Path)
+	if !ok || (rel != "" && rel[0] != '/') {
+		return "", false
+	}
+
+	root, ok := strings.CutSuffix(path.Dir(filepath.ToSlash(frame.File)), rel)
+	if !ok || root == "" || root == "." {
+		return "", false
+	}
+
+	return root, true
+}
+
+// escapeModulePath escapes the module path or version the way the module cache does:
+// upper case letters are replaced with an exclamation mark followed by the lower case letter.
+func escapeModulePath(s string) string {
+	if strings.IndexFunc(s, unicode.IsUpper) < 0 {
+		return s
+	}
+
+	var buf strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			buf.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,86 @@
+package errors_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sirkon/errors"
+)
+
+func ExampleTrimLocations() {
+	errors.InsertLocations()
+	defer errors.DoNotInsertLocations()
+	defer errors.TrimLocations(nil)
+
+	for _, trimmer := range []errors.LocationTrimmer{
+		errors.TrimModuleRoot(),
+		errors.TrimModulePath(),
+	} {
+		errors.TrimLocations(trimmer)
+
+		err := errors.New("error")
+		for _, attr := range errors.SLogTreeContext(err) {
+			fmt.Println(attr)
+		}
+	}
+
+	// Output:
+	// NEW: error=[@location=loc_trim_test.go:23]
+	// NEW: error=[@location=github.com/sirkon/errors/loc_trim_test.go:23]
+}
+
+func TestTrimModule(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	root := filepath.ToSlash(filepath.Dir(file))
+
+	// None of these paths but the first one exist, modules are known from the build info only.
+	tests := []struct {
+		file   string
+		root   string
+		module string
+	}{
+		{
+			file:   file,
+			root:   "loc_trim_test.go",
+			module: "github.com/sirkon/errors/loc_trim_test.go",
+		},
+		{
+			file:   "github.com/sirkon/errors/errorsctx/x.go",
+			root:   "errorsctx/x.go",
+			module: "github.com/sirkon/errors/errorsctx/x.go",
+		},
+		{
+			file:   "/home/user/go/pkg/mod/google.golang.org/protobuf@v1.27.1/proto/encode.go",
+			root:   "google.golang.org/protobuf@v1.27.1/proto/encode.go",
+			module: "google.golang.org/protobuf@v1.27.1/proto/encode.go",
+		},
+		{
+			file:   "google.golang.org/protobuf@v1.27.1/proto/encode.go",
+			root:   "google.golang.org/protobuf@v1.27.1/proto/encode.go",
+			module: "google.golang.org/protobuf@v1.27.1/proto/encode.go",
+		},
+		{
+			file:   root + "/vendor/google.golang.org/protobuf/proto/encode.go",
+			root:   "google.golang.org/protobuf/proto/encode.go",
+			module: "google.golang.org/protobuf/proto/encode.go",
+		},
+		{
+			file:   "/usr/lib/go/src/fmt/print.go",
+			root:   "/usr/lib/go/src/fmt/print.go",
+			module: "/usr/lib/go/src/fmt/print.go",
+		},
+	}
+
+	trimRoot := errors.TrimModuleRoot()
+	trimPath := errors.TrimModulePath()
+	for _, tt := range tests {
+		if got := trimRoot(tt.file); got != tt.root {
+			t.Errorf("TrimModuleRoot(%q) = %q, want %q", tt.file, got, tt.root)
+		}
+		if got := trimPath(tt.file); got != tt.module {
+			t.Errorf("TrimModulePath(%q) = %q, want %q", tt.file, got, tt.module)
+		}
+	}
+}
@@ -6,35 +6,64 @@ type errorContextDeliverer struct {
 
 func (e *errorContextDeliverer) Deliver(cons ErrorContextConsumer) {
 	var layer ErrorContextBuilder
+	var specs []string
 
 	for _, attr := range e.tgt.attrs {
 		switch attr.kind {
 		case errorAttrKindNew:
-			finalizeErrContextBuilder(layer)
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
+			layer = cons.New(attr.key)
+		case errorAttrKindOutterNew:
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
+			dlv := GetContextDeliverer(attr.value.Any().(error))
+			if dlv != nil {
+				dlv.Deliver(cons)
+			}
 			layer = cons.New(attr.key)
 		case errorAttrKindWrap:
-			finalizeErrContextBuilder(layer)
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
 			layer = cons.Wrap(attr.key)
 		case errorAttrKindOutterWrap:
-			finalizeErrContextBuilder(layer)
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
 			dlv := GetContextDeliverer(attr.value.Any().(error))
 			if dlv != nil {
 				dlv.Deliver(cons)
 			}
 			layer = cons.Wrap(attr.key)
 		case errorAttrKindJust:
-			finalizeErrContextBuilder(layer)
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
 			layer = cons.Just()
 		case errorAttrKindOutterJust:
-			finalizeErrContextBuilder(layer)
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
+			layer = cons.Just()
+			dlv := GetContextDeliverer(attr.value.Any().(error))
+			if dlv != nil {
+				dlv.Deliver(cons)
+			}
 			layer = cons.Just()
+		case errorAttrKindPhantomJust:
+			// Контекст, данный поверх чужой ошибки без своего слоя, показываем как CTX.
+			finalizeErrContextBuilder(layer, specs)
+			specs = nil
 			dlv := GetContextDeliverer(attr.value.Any().(error))
 			if dlv != nil {
 				dlv.Deliver(cons)
 			}
 			layer = cons.Just()
 		case errorAttrKindLoc:
-			layer.Loc(attr.value.String())
+			layer.Loc(trimLocation(attr.value.String()))
+		case errorAttrKindRemote:
+			layer.Any("@remote", attr.value.Any())
+		case errorAttrKindMarker:
+			if showSpecs {
+				specs = append(specs, specValue(attr.mark()).String())
+			}
 		case errorAttrKindBool:
 			layer.Bool(attr.key, attr.value.Bool())
 		case errorAttrKindI64:
@@ -50,18 +79,26 @@ func (e *errorContextDeliverer) Deliver(cons ErrorContextConsumer) {
 		default:
 			panic(Newf("invalid errorAttrKind value %d for key %q", attr.kind, attr.key))
 		}
-	}
 
-	if layer != nil {
-		layer.Finalize()
+		if showSpecs {
+			for _, mark := range layerSentinelSpecs(attr) {
+				specs = append(specs, specValue(mark).String())
+			}
+		}
 	}
+
+	finalizeErrContextBuilder(layer, specs)
 }
 
 func (e *errorContextDeliverer) Error() string { return "" }
 
-func finalizeErrContextBuilder(layer ErrorContextBuilder) {
+// finalizeErrContextBuilder finalizes the layer, specs of the layer are given as a single @spec value.
+func finalizeErrContextBuilder(layer ErrorContextBuilder, specs []string) {
 	if layer == nil {
 		return
 	}
+	if len(specs) > 0 {
+		layer.Any("@spec", specs)
+	}
 	layer.Finalize()
 }
@@ -34,3 +34,25 @@ func ExampleGetContextDeliverer() {
 	//     this-is-the-last: true
 	//     real-raw: [1 2 3]
 }
+
+func ExampleGetContextDeliverer_foreign() {
+	inner := errors.New("deep").Int("depth", 1)
+	err := errors.Wrap(errors.Spec(fmt.Errorf("foreign: %w", inner), 1).Str("k", "v"), "outer")
+
+	var c errorsctx.Consumer
+	errors.GetContextDeliverer(err).Deliver(&c)
+
+	for _, layer := range c.Layers {
+		fmt.Println(layer)
+		for _, pair := range layer.Pairs {
+			fmt.Printf("    %s: %v\n", pair.Key, pair.Value.Any())
+		}
+	}
+
+	// Output:
+	// NEW: deep
+	//     depth: 1
+	// CTX
+	//     k: v
+	// WRAP: outer
+}
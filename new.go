@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"log/slog"
 )
 
 // New creates new error with the given text.
@@ -37,3 +38,38 @@ func Newf(format string, a ...any) *Error {
 
 	return res
 }
+
+// From creates new error starting from the given one, usually a sentinel, to attach context to it.
+// Its text is exactly the text of the given error and it matches it with [Is]:
+//
+//	return errors.From(ErrNotFound).Str("user", name)
+//
+// An *Error is returned as is. A nil error is a programming mistake, yet it gives
+// an error with the "<nil>" text matching nothing instead of a panic.
+func From(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+
+	res := &Error{
+		attrs: make([]errorAttr, 0, errorContextLengthPrediction),
+	}
+	if err == nil {
+		res.attrs = append(res.attrs, errorAttr{
+			kind: errorAttrKindNew,
+			key:  "<nil>",
+		})
+	} else {
+		res.attrs = append(res.attrs, errorAttr{
+			kind:  errorAttrKindOutterNew,
+			key:   err.Error(),
+			value: slog.AnyValue(err),
+		})
+	}
+
+	if insertLocations {
+		res.setLoc(2)
+	}
+
+	return res
+}
@@ -2,26 +2,121 @@ package errors
 
 import (
 	"fmt"
+	"sync"
 )
 
 // NewSentinel creates static error that can be a sentinel.
-func NewSentinel(msg string) error {
-	return &errorSentinel{
+func NewSentinel(msg string, opts ...SentinelOption) error {
+	res := &errorSentinel{
 		value: msg,
 	}
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	if res.id != "" {
+		registerSentinel(res)
+	}
+
+	return res
+}
+
+// NewSentinelUnder creates a sentinel which is a special case of the parent one: [Is] matches
+// it against the parent as well as against itself, through any wrapping.
+//
+//	var (
+//		ErrNotFound     = errors.NewSentinel("not found")
+//		ErrUserNotFound = errors.NewSentinelUnder(ErrNotFound, "user not found")
+//	)
+func NewSentinelUnder(parent error, msg string, opts ...SentinelOption) error {
+	res := NewSentinel(msg, opts...).(*errorSentinel)
+	res.parent = parent
+	return res
 }
 
 // NewSentinelf same as NewSentinel, just with a format instead of a static message.
+// It takes no options, use NewSentinel with [fmt.Sprintf] for sentinels that need them:
+//
+//	var ErrTooLarge = errors.NewSentinel(fmt.Sprintf("larger than %d bytes", maxSize), errors.SentinelID("storage.too-large"))
 func NewSentinelf(format string, a ...any) error {
 	return &errorSentinel{
 		value: fmt.Sprintf(format, a...),
 	}
 }
 
+// SentinelOption tunes sentinels created with [NewSentinel].
+type SentinelOption func(*errorSentinel)
+
+// SentinelID registers the sentinel under the given stable identifier. Serialized errors
+// carry identifiers of sentinels they wrap and decoding maps them back to local sentinels,
+// so [Is] works on the receiving side too:
+//
+//	var ErrNotFound = errors.NewSentinel("not found", errors.SentinelID("storage.not-found"))
+//
+// Identifiers must be unique, [NewSentinel] panics when the identifier is already taken.
+func SentinelID(id string) SentinelOption {
+	return func(s *errorSentinel) {
+		s.id = id
+	}
+}
+
+// WithSpec gives the sentinel a spec, like [Spec] does with errors. [AsSpec] and [IsSpec]
+// find it through any wrapping of the sentinel and of its children made with [NewSentinelUnder]:
+//
+//	var ErrNotFound = errors.NewSentinel("not found", errors.WithSpec(HTTPStatus(http.StatusNotFound)))
+func WithSpec(mark any) SentinelOption {
+	return func(s *errorSentinel) {
+		s.specs = append(s.specs, mark)
+	}
+}
+
 type errorSentinel struct {
-	value string
+	value  string
+	id     string
+	parent error
+	specs  []any
 }
 
 func (e *errorSentinel) Error() string {
 	return e.value
 }
+
+// Unwrap returns the parent of the sentinel.
+func (e *errorSentinel) Unwrap() error {
+	return e.parent
+}
+
+var sentinels sync.Map
+
+func registerSentinel(s *errorSentinel) {
+	if prev, loaded := sentinels.LoadOrStore(s.id, s); loaded {
+		panic(Newf("sentinel id %q is already taken by %q", s.id, prev.(*errorSentinel).value))
+	}
+}
+
+// lookupSentinel returns the sentinel registered under the given identifier.
+func lookupSentinel(id string) (*errorSentinel, bool) {
+	s, ok := sentinels.Load(id)
+	if !ok {
+		return nil, false
+	}
+
+	return s.(*errorSentinel), true
+}
+
+// sentinelID returns the identifier of the first registered sentinel found in the chain of the error.
+// Unregistered sentinels are skipped in favor of their registered parents.
+func sentinelID(err error) string {
+	for err != nil {
+		s, ok := AsType[*errorSentinel](err)
+		if !ok {
+			return ""
+		}
+		if s.id != "" {
+			return s.id
+		}
+		err = s.parent
+	}
+
+	return ""
+}
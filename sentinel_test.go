@@ -0,0 +1,174 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
+)
+
+var errSnapshotNotFound = errors.NewSentinel("not found", errors.SentinelID("errors-test.not-found"))
+
+func ExampleSentinelID() {
+	err := errors.Wrap(fmt.Errorf("get user: %w", errSnapshotNotFound), "handle request").Str("user", "joe")
+	data, _ := json.Marshal(err)
+
+	decoded, _ := errors.Decode(data)
+	fmt.Println(decoded)
+	fmt.Println(errors.Is(decoded, errSnapshotNotFound))
+
+	data, _ = json.Marshal(errors.From(errSnapshotNotFound).Str("user", "joe"))
+	decoded, _ = errors.Decode(data)
+	fmt.Println(decoded)
+	fmt.Println(errors.Is(decoded, errSnapshotNotFound))
+
+	// Output:
+	// handle request: get user: not found
+	// true
+	// not found
+	// true
+}
+
+func TestSentinelIDTaken(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic on the taken sentinel id")
+		}
+	}()
+
+	errors.NewSentinel("not found again", errors.SentinelID("errors-test.not-found"))
+}
+
+func TestSentinelIDUnknown(t *testing.T) {
+	data := []byte(`{"layers":[{"kind":"just","cause":{"text":"not found","sentinel":"errors-test.unknown"}}]}`)
+	decoded, err := errors.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Error() != "not found" {
+		t.Errorf("unexpected text %q", decoded.Error())
+	}
+	if errors.Is(decoded, errSnapshotNotFound) {
+		t.Error("unknown sentinel must not match")
+	}
+}
+
+func ExampleNewSentinelUnder() {
+	var (
+		errNotFound     = errors.NewSentinel("not found")
+		errUserNotFound = errors.NewSentinelUnder(errNotFound, "user not found")
+		errConflict     = errors.NewSentinel("conflict")
+	)
+
+	for _, err := range []error{
+		errUserNotFound,
+		errors.Wrap(errUserNotFound, "get user").Str("user", "joe"),
+		errors.Just(fmt.Errorf("lookup: %w", errUserNotFound)).Int("attempt", 2),
+		errors.Spec(errUserNotFound, new(1)),
+		errors.Wrap(errNotFound, "get order"),
+	} {
+		fmt.Println(
+			errors.Is(err, errUserNotFound),
+			errors.Is(err, errNotFound),
+			errors.Is(err, errConflict),
+		)
+	}
+
+	// Output:
+	// true true false
+	// true true false
+	// true true false
+	// true true false
+	// false true false
+}
+
+func ExampleFrom() {
+	errNotFound := errors.NewSentinel("not found")
+
+	err := errors.From(errNotFound).Str("user", "joe")
+	fmt.Println(err, errors.Is(err, errNotFound))
+	fmt.Println(string(err.AppendJSON(nil, errors.ContextTree)))
+
+	wrapped := errors.Wrap(err, "get user")
+	fmt.Println(wrapped, errors.Is(wrapped, errNotFound))
+
+	// Output:
+	// not found true
+	// {"NEW: not found":{"user":"joe"}}
+	// get user: not found true
+}
+
+func TestFromInner(t *testing.T) {
+	err := errors.From(fmt.Errorf("outer: %w", errors.New("deep").Int("k", 1))).Str("user", "joe")
+
+	if got, want := fmt.Sprint(errors.SLogFlatContext(err)), "[k=1 user=joe @locations=[]]"; got != want {
+		t.Errorf("flat context %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprint(errors.SLogTreeContext(err)), "[NEW: deep=[=<nil> k=1] NEW: outer: deep=[=<nil> user=joe]]"; got != want {
+		t.Errorf("tree context %s, want %s", got, want)
+	}
+	if got, want := string(err.AppendJSON(nil, errors.ContextFlat)), `{"k":1,"user":"joe"}`; got != want {
+		t.Errorf("flat JSON %s, want %s", got, want)
+	}
+	if got, want := string(err.AppendJSON(nil, errors.ContextTree)), `{"NEW: deep":{"k":1},"NEW: outer: deep":{"user":"joe"}}`; got != want {
+		t.Errorf("tree JSON %s, want %s", got, want)
+	}
+
+	var c errorsctx.Consumer
+	errors.GetContextDeliverer(err).Deliver(&c)
+	if got, want := fmt.Sprint(c.Layers), "[NEW: deep NEW: outer: deep]"; got != want {
+		t.Errorf("delivered %s, want %s", got, want)
+	}
+}
+
+func TestFromNil(t *testing.T) {
+	err := errors.From(nil).Int("k", 1)
+	if err.Error() != "<nil>" {
+		t.Errorf("unexpected text %q", err.Error())
+	}
+}
+
+func TestSentinelIDDecodedChain(t *testing.T) {
+	type status int
+
+	var (
+		errBase = errors.NewSentinel("storage failure", errors.WithSpec(status(500)))
+		errGone = errors.NewSentinelUnder(errBase, "gone", errors.SentinelID("errors-test.gone"))
+	)
+
+	data, _ := json.Marshal(errors.Wrap(errGone, "read"))
+	decoded, err := errors.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(decoded, errGone) || !errors.Is(decoded, errBase) {
+		t.Error("decoded error must match the sentinel and its parent")
+	}
+	if s, ok := errors.AsSpec[status](decoded); !ok || s != 500 {
+		t.Errorf("spec of the sentinel %d %v", s, ok)
+	}
+
+	// Разобранная внешняя ошибка разворачивается в ошибку со своим контекстом.
+	data, _ = json.Marshal(errors.Wrap(fmt.Errorf("%w: %w", errGone, errors.New("row deleted").Int("id", 3)), "read"))
+	decoded, err = errors.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cause interface{ Unwrap() error }
+	if !errors.As(decoded, &cause) {
+		t.Fatal("decoded cause does not unwrap to a single error")
+	}
+	inner, ok := cause.Unwrap().(*errors.Error)
+	if !ok {
+		t.Fatalf("unwrapped to %T", cause.Unwrap())
+	}
+	if got := fmt.Sprint(errors.SLogFlatContext(inner)); got != "[id=3 @locations=[]]" {
+		t.Errorf("context %s", got)
+	}
+	if !errors.Is(decoded, errGone) {
+		t.Error("decoded error with context must match the sentinel")
+	}
+}
@@ -0,0 +1,350 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+var serviceName string
+
+// SetServiceName sets the name of the service put into serialized errors. Layers of errors
+// decoded on the other side are marked as remote ones coming from this service.
+// It is meant to be called once at the start of the program.
+func SetServiceName(name string) {
+	serviceName = name
+}
+
+// LayerKind is a kind of error layer in a [Snapshot].
+type LayerKind string
+
+const (
+	// LayerNew is a layer created with [New].
+	LayerNew LayerKind = "new"
+	// LayerWrap is a layer created with [Wrap].
+	LayerWrap LayerKind = "wrap"
+	// LayerJust is a layer created with [Just].
+	LayerJust LayerKind = "just"
+	// LayerCause is a foreign error without a layer of its own, like the one given to [Spec].
+	LayerCause LayerKind = "cause"
+)
+
+// Snapshot is a serializable representation of an [Error], it is what [Error.MarshalJSON]
+// encodes and the source of other wire formats. Layers go from the innermost to the outermost.
+type Snapshot struct {
+	Layers []SnapshotLayer
+}
+
+// SnapshotLayer is a single layer of an error.
+//
+// Attribute values are limited to kinds that can be carried across process boundaries:
+// basic kinds, durations, times and groups of them, and Any values holding []byte,
+// error or [json.RawMessage]. Other values are replaced with their JSON encoding.
+// Error values keep only their texts, [FromSnapshot] rebuilds them as remote errors.
+type SnapshotLayer struct {
+	Kind LayerKind
+	// Message is a text of the layer, it is empty for Just layers.
+	Message string
+	// Cause is set for layers over foreign errors, New layers have it when created with [From].
+	Cause *SnapshotCause
+	// Location is a location of the layer if it was recorded.
+	Location string
+	// Service is a name of the service the layer was created in.
+	Service string
+	Attrs   []slog.Attr
+}
+
+// SnapshotCause is a foreign error wrapped by a layer.
+type SnapshotCause struct {
+	// Text is a text of the foreign error.
+	Text string
+	// Sentinel is an identifier of the registered sentinel in the chain of the foreign error, see [SentinelID].
+	Sentinel string
+	// Layers are layers of an [Error] found in the chain of the foreign error.
+	Layers []SnapshotLayer
+}
+
+// Snapshot returns a serializable representation of the error.
+func (e *Error) Snapshot() *Snapshot {
+	return &Snapshot{
+		Layers: snapshotLayers(e.attrs),
+	}
+}
+
+// FromSnapshot rebuilds an error from its snapshot. Layers of the result are marked
+// as remote ones and are rendered with the @remote value holding their service name.
+func FromSnapshot(s *Snapshot) (*Error, error) {
+	if len(s.Layers) == 0 {
+		return nil, New("no layers in error snapshot")
+	}
+
+	return fromSnapshotLayers(s.Layers)
+}
+
+func snapshotLayers(attrs []errorAttr) []SnapshotLayer {
+	layers := make([]SnapshotLayer, 0, errorContextNoOfStages)
+	layer := func() *SnapshotLayer {
+		if len(layers) == 0 {
+			// Значения до первого слоя не могут появиться в правильно собранной ошибке, но терять их не стоит.
+			layers = append(layers, SnapshotLayer{Kind: LayerJust, Service: serviceName})
+		}
+		return &layers[len(layers)-1]
+	}
+
+	for _, attr := range attrs {
+		switch attr.kind {
+		case errorAttrKindMarker:
+			continue
+		case errorAttrKindNew:
+			layers = append(layers, SnapshotLayer{Kind: LayerNew, Message: attr.key, Service: serviceName})
+		case errorAttrKindOutterNew:
+			layers = append(layers, SnapshotLayer{
+				Kind:    LayerNew,
+				Message: attr.key,
+				Cause:   snapshotCause(attr),
+				Service: serviceName,
+			})
+		case errorAttrKindWrap:
+			layers = append(layers, SnapshotLayer{Kind: LayerWrap, Message: attr.key, Service: serviceName})
+		case errorAttrKindOutterWrap:
+			layers = append(layers, SnapshotLayer{
+				Kind:    LayerWrap,
+				Message: attr.key,
+				Cause:   snapshotCause(attr),
+				Service: serviceName,
+			})
+		case errorAttrKindJust:
+			layers = append(layers, SnapshotLayer{Kind: LayerJust, Service: serviceName})
+		case errorAttrKindOutterJust:
+			layers = append(layers, SnapshotLayer{Kind: LayerJust, Cause: snapshotCause(attr), Service: serviceName})
+		case errorAttrKindPhantomJust:
+			layers = append(layers, SnapshotLayer{Kind: LayerCause, Cause: snapshotCause(attr), Service: serviceName})
+		case errorAttrKindLoc:
+			layer().Location = trimLocation(attr.value.String())
+		case errorAttrKindRemote:
+			layer().Service = attr.key
+		default:
+			l := layer()
+			l.Attrs = append(l.Attrs, slog.Attr{
+				Key:   attr.key,
+				Value: snapshotValue(attr.value),
+			})
+		}
+	}
+
+	return layers
+}
+
+func snapshotCause(attr errorAttr) *SnapshotCause {
+	err, ok := attr.value.Any().(error)
+	if !ok {
+		return &SnapshotCause{Text: fmt.Sprint(attr.value.Any())}
+	}
+
+	res := &SnapshotCause{
+		Text:     err.Error(),
+		Sentinel: sentinelID(err),
+	}
+	if e, ok := AsType[*Error](err); ok {
+		res.Layers = snapshotLayers(e.attrs)
+	}
+
+	return res
+}
+
+// snapshotValue brings the value to kinds a snapshot can carry.
+func snapshotValue(v slog.Value) slog.Value {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, attr := range group {
+			attrs[i] = slog.Attr{Key: attr.Key, Value: snapshotValue(attr.Value)}
+		}
+		return slog.GroupValue(attrs...)
+	case slog.KindAny:
+	default:
+		return v
+	}
+
+	switch x := v.Any().(type) {
+	case []byte, error, json.RawMessage:
+		return v
+	default:
+		data, err := json.Marshal(x)
+		if err != nil {
+			return slog.StringValue(fmt.Sprint(x))
+		}
+		return slog.AnyValue(json.RawMessage(data))
+	}
+}
+
+func fromSnapshotLayers(layers []SnapshotLayer) (*Error, error) {
+	res := &Error{
+		attrs: make([]errorAttr, 0, max(errorContextLengthPrediction, 3*len(layers))),
+	}
+
+	for i, layer := range layers {
+		var kind errorAttrKind
+		switch layer.Kind {
+		case LayerNew:
+			kind = errorAttrKindNew
+			if layer.Cause != nil {
+				kind = errorAttrKindOutterNew
+			}
+		case LayerWrap:
+			kind = errorAttrKindWrap
+			if layer.Cause != nil {
+				kind = errorAttrKindOutterWrap
+			}
+		case LayerJust:
+			kind = errorAttrKindJust
+			if layer.Cause != nil {
+				kind = errorAttrKindOutterJust
+			}
+		case LayerCause:
+			kind = errorAttrKindPhantomJust
+		default:
+			return nil, Newf("invalid kind %q of layer %d", layer.Kind, i)
+		}
+
+		head := errorAttr{
+			kind: kind,
+			key:  layer.Message,
+		}
+		if layer.Cause != nil {
+			cause, err := fromSnapshotCause(layer.Cause)
+			if err != nil {
+				return nil, Wrapf(err, "decode cause of layer %d", i)
+			}
+			head.value = slog.AnyValue(cause)
+		} else if kind == errorAttrKindPhantomJust {
+			return nil, Newf("missing cause of layer %d", i)
+		}
+		res.attrs = append(res.attrs, head)
+
+		if layer.Location != "" {
+			res.attrs = append(res.attrs, errorAttr{
+				kind:  errorAttrKindLoc,
+				value: slog.StringValue(layer.Location),
+			})
+		}
+
+		remote := slog.BoolValue(true)
+		if layer.Service != "" {
+			remote = slog.StringValue(layer.Service)
+		}
+		res.attrs = append(res.attrs, errorAttr{
+			kind:  errorAttrKindRemote,
+			key:   layer.Service,
+			value: remote,
+		})
+
+		for _, attr := range layer.Attrs {
+			value := remoteValue(attr.Value)
+			res.attrs = append(res.attrs, errorAttr{
+				kind:  snapshotAttrKind(value),
+				key:   attr.Key,
+				value: value,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func fromSnapshotCause(c *SnapshotCause) (error, error) {
+	res := &remoteError{text: c.Text}
+	if s, ok := lookupSentinel(c.Sentinel); ok {
+		res.sentinel = s
+	}
+	if len(c.Layers) == 0 {
+		return res, nil
+	}
+
+	inner, err := fromSnapshotLayers(c.Layers)
+	if err != nil {
+		return nil, err
+	}
+	res.inner = inner
+
+	return res, nil
+}
+
+// remoteValue turns error values into remote errors, whatever wire format they came from.
+func remoteValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, attr := range group {
+			attrs[i] = slog.Attr{Key: attr.Key, Value: remoteValue(attr.Value)}
+		}
+		return slog.GroupValue(attrs...)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case *remoteError:
+			return v
+		case error:
+			return slog.AnyValue(&remoteError{text: x.Error()})
+		}
+	}
+
+	return v
+}
+
+func snapshotAttrKind(v slog.Value) errorAttrKind {
+	switch v.Kind() {
+	case slog.KindBool:
+		return errorAttrKindBool
+	case slog.KindInt64:
+		return errorAttrKindI64
+	case slog.KindUint64:
+		return errorAttrKindU64
+	case slog.KindFloat64:
+		return errorAttrKindF64
+	case slog.KindString:
+		return errorAttrKindStr
+	default:
+		return errorAttrKindAny
+	}
+}
+
+// remoteError is a foreign error decoded from a snapshot. Its context, if any, is
+// available through the [Error] it wraps, and it matches the local sentinel it was
+// created from on the other side.
+type remoteError struct {
+	text     string
+	inner    *Error
+	sentinel *errorSentinel
+}
+
+func (e *remoteError) Error() string {
+	return e.text
+}
+
+// Unwrap returns the decoded error the foreign one wrapped, if any.
+func (e *remoteError) Unwrap() error {
+	if e.inner == nil {
+		return nil
+	}
+
+	return e.inner
+}
+
+// Is matches the local sentinel the error was created from and its parents.
+func (e *remoteError) Is(target error) bool {
+	return e.sentinel != nil && Is(e.sentinel, target)
+}
+
+// As gives the local sentinel the error was created from, so specs of sentinels are found.
+func (e *remoteError) As(target any) bool {
+	s, ok := target.(**errorSentinel)
+	if !ok || e.sentinel == nil {
+		return false
+	}
+
+	*s = e.sentinel
+	return true
+}
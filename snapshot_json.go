@@ -0,0 +1,300 @@
+package errors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+)
+
+// MarshalJSON encodes the error with its layers, locations and typed context values, so it
+// can be rebuilt with [Decode] on the other side of a service boundary. See [Snapshot] for
+// what is kept.
+//
+// Mind this encoding is used wherever errors are encoded as JSON values: [json.Marshal]
+// gives the whole context and locations instead of a text, [slog.JSONHandler] logs errors
+// put with [slog.Any] this way too. Use the text explicitly where it is needed:
+//
+//	logger.Error("failed", slog.String("err", err.Error()))
+//
+// A nil error is encoded as null.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(jsonSnapshot{Layers: jsonLayers(e.Snapshot().Layers)})
+}
+
+// UnmarshalJSON rebuilds the error encoded with [Error.MarshalJSON], see [Decode].
+func (e *Error) UnmarshalJSON(data []byte) error {
+	res, err := Decode(data)
+	if err != nil {
+		return err
+	}
+
+	*e = *res
+	return nil
+}
+
+// Decode rebuilds the error encoded with [Error.MarshalJSON]. Layers of the result
+// are marked as remote ones, see [FromSnapshot].
+func Decode(data []byte) (*Error, error) {
+	var wire jsonSnapshot
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, Wrap(err, "decode error snapshot")
+	}
+
+	layers, err := fromJSONLayers(wire.Layers)
+	if err != nil {
+		return nil, Wrap(err, "decode error snapshot")
+	}
+
+	return FromSnapshot(&Snapshot{Layers: layers})
+}
+
+type jsonSnapshot struct {
+	Layers []jsonSnapshotLayer `json:"layers"`
+}
+
+type jsonSnapshotLayer struct {
+	Kind     LayerKind          `json:"kind"`
+	Message  string             `json:"msg,omitempty"`
+	Cause    *jsonSnapshotCause `json:"cause,omitempty"`
+	Location string             `json:"loc,omitempty"`
+	Service  string             `json:"service,omitempty"`
+	Attrs    []jsonSnapshotAttr `json:"attrs,omitempty"`
+}
+
+type jsonSnapshotCause struct {
+	Text     string              `json:"text"`
+	Sentinel string              `json:"sentinel,omitempty"`
+	Layers   []jsonSnapshotLayer `json:"layers,omitempty"`
+}
+
+// jsonSnapshotAttr keeps value type explicitly, JSON alone cannot tell int64 from uint64
+// or a string from a time.
+type jsonSnapshotAttr struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+const (
+	jsonAttrBool     = "bool"
+	jsonAttrInt64    = "int64"
+	jsonAttrUint64   = "uint64"
+	jsonAttrFloat64  = "float64"
+	jsonAttrString   = "string"
+	jsonAttrBytes    = "bytes"
+	jsonAttrDuration = "duration"
+	jsonAttrTime     = "time"
+	jsonAttrError    = "error"
+	jsonAttrGroup    = "group"
+	jsonAttrJSON     = "json"
+)
+
+func jsonLayers(layers []SnapshotLayer) []jsonSnapshotLayer {
+	res := make([]jsonSnapshotLayer, len(layers))
+	for i, layer := range layers {
+		res[i] = jsonSnapshotLayer{
+			Kind:     layer.Kind,
+			Message:  layer.Message,
+			Location: layer.Location,
+			Service:  layer.Service,
+			Attrs:    jsonAttrs(layer.Attrs),
+		}
+		if layer.Cause != nil {
+			res[i].Cause = &jsonSnapshotCause{
+				Text:     layer.Cause.Text,
+				Sentinel: layer.Cause.Sentinel,
+				Layers:   jsonLayers(layer.Cause.Layers),
+			}
+		}
+	}
+
+	return res
+}
+
+func jsonAttrs(attrs []slog.Attr) []jsonSnapshotAttr {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	res := make([]jsonSnapshotAttr, len(attrs))
+	for i, attr := range attrs {
+		res[i] = jsonAttr(attr)
+	}
+
+	return res
+}
+
+func jsonAttr(attr slog.Attr) jsonSnapshotAttr {
+	res := jsonSnapshotAttr{Key: attr.Key}
+
+	v := attr.Value
+	switch v.Kind() {
+	case slog.KindBool:
+		res.Type = jsonAttrBool
+		res.Value = strconv.AppendBool(nil, v.Bool())
+	case slog.KindInt64:
+		res.Type = jsonAttrInt64
+		res.Value = strconv.AppendInt(nil, v.Int64(), 10)
+	case slog.KindUint64:
+		res.Type = jsonAttrUint64
+		res.Value = strconv.AppendUint(nil, v.Uint64(), 10)
+	case slog.KindFloat64:
+		res.Type = jsonAttrFloat64
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			// Не представимые в JSON значения передаются строками.
+			res.Value = appendJSONString(nil, strconv.FormatFloat(f, 'g', -1, 64))
+		} else {
+			res.Value = strconv.AppendFloat(nil, f, 'g', -1, 64)
+		}
+	case slog.KindString:
+		res.Type = jsonAttrString
+		res.Value = appendJSONString(nil, v.String())
+	case slog.KindDuration:
+		res.Type = jsonAttrDuration
+		res.Value = strconv.AppendInt(nil, int64(v.Duration()), 10)
+	case slog.KindTime:
+		res.Type = jsonAttrTime
+		res.Value = appendJSONString(nil, v.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		res.Type = jsonAttrGroup
+		res.Value, _ = json.Marshal(jsonAttrs(v.Group()))
+		if len(v.Group()) == 0 {
+			res.Value = json.RawMessage("[]")
+		}
+	default:
+		switch x := snapshotValue(v).Any().(type) {
+		case []byte:
+			res.Type = jsonAttrBytes
+			res.Value = appendJSONString(nil, base64.StdEncoding.EncodeToString(x))
+		case error:
+			res.Type = jsonAttrError
+			res.Value = appendJSONString(nil, x.Error())
+		case json.RawMessage:
+			res.Type = jsonAttrJSON
+			res.Value = x
+		default:
+			// Значения, которые не удалось закодировать в JSON, стали строками.
+			return jsonAttr(slog.Attr{Key: attr.Key, Value: snapshotValue(v)})
+		}
+	}
+
+	return res
+}
+
+func fromJSONLayers(layers []jsonSnapshotLayer) ([]SnapshotLayer, error) {
+	res := make([]SnapshotLayer, len(layers))
+	for i, layer := range layers {
+		attrs, err := fromJSONAttrs(layer.Attrs)
+		if err != nil {
+			return nil, Wrapf(err, "decode attributes of layer %d", i)
+		}
+
+		res[i] = SnapshotLayer{
+			Kind:     layer.Kind,
+			Message:  layer.Message,
+			Location: layer.Location,
+			Service:  layer.Service,
+			Attrs:    attrs,
+		}
+		if layer.Cause == nil {
+			continue
+		}
+
+		causeLayers, err := fromJSONLayers(layer.Cause.Layers)
+		if err != nil {
+			return nil, Wrapf(err, "decode cause of layer %d", i)
+		}
+		res[i].Cause = &SnapshotCause{
+			Text:     layer.Cause.Text,
+			Sentinel: layer.Cause.Sentinel,
+			Layers:   causeLayers,
+		}
+	}
+
+	return res, nil
+}
+
+func fromJSONAttrs(attrs []jsonSnapshotAttr) ([]slog.Attr, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	res := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		v, err := fromJSONAttrValue(attr)
+		if err != nil {
+			return nil, Wrapf(err, "decode %s value of %q", attr.Type, attr.Key)
+		}
+		res[i] = slog.Attr{Key: attr.Key, Value: v}
+	}
+
+	return res, nil
+}
+
+func fromJSONAttrValue(attr jsonSnapshotAttr) (slog.Value, error) {
+	switch attr.Type {
+	case jsonAttrBool:
+		var v bool
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.BoolValue(v), err
+	case jsonAttrInt64:
+		var v int64
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.Int64Value(v), err
+	case jsonAttrUint64:
+		var v uint64
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.Uint64Value(v), err
+	case jsonAttrFloat64:
+		var s string
+		if json.Unmarshal(attr.Value, &s) == nil {
+			v, err := strconv.ParseFloat(s, 64)
+			return slog.Float64Value(v), err
+		}
+		var v float64
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.Float64Value(v), err
+	case jsonAttrString:
+		var v string
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.StringValue(v), err
+	case jsonAttrBytes:
+		var v []byte
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.AnyValue(v), err
+	case jsonAttrDuration:
+		var v int64
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.DurationValue(time.Duration(v)), err
+	case jsonAttrTime:
+		var v time.Time
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.TimeValue(v), err
+	case jsonAttrError:
+		var v string
+		err := json.Unmarshal(attr.Value, &v)
+		return slog.AnyValue(&remoteError{text: v}), err
+	case jsonAttrGroup:
+		var group []jsonSnapshotAttr
+		if err := json.Unmarshal(attr.Value, &group); err != nil {
+			return slog.Value{}, err
+		}
+		attrs, err := fromJSONAttrs(group)
+		return slog.GroupValue(attrs...), err
+	case jsonAttrJSON:
+		if !json.Valid(attr.Value) {
+			return slog.Value{}, New("invalid JSON")
+		}
+		return slog.AnyValue(attr.Value), nil
+	default:
+		return slog.Value{}, Newf("unknown value type %q", attr.Type)
+	}
+}
@@ -0,0 +1,115 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/sirkon/errors"
+)
+
+func ExampleDecode() {
+	errors.SetServiceName("billing")
+	defer errors.SetServiceName("")
+
+	err := errors.New("connection refused").Int("port", 5432)
+	err = errors.Wrap(err, "connect").Str("db", "users")
+	data, _ := json.Marshal(err)
+	fmt.Println(string(data))
+
+	// The other side.
+	errors.SetServiceName("gateway")
+	decoded, _ := errors.Decode(data)
+	decoded = errors.Wrap(decoded, "charge").U64("amount", 100)
+	fmt.Println(decoded)
+	fmt.Println(string(decoded.AppendJSON(nil, errors.ContextTree)))
+
+	// Output:
+	// {"layers":[{"kind":"new","msg":"connection refused","service":"billing","attrs":[{"key":"port","type":"int64","value":5432}]},{"kind":"wrap","msg":"connect","service":"billing","attrs":[{"key":"db","type":"string","value":"users"}]}]}
+	// charge: connect: connection refused
+	// {"NEW: connection refused":{"@remote":"billing","port":5432},"WRAP: connect":{"@remote":"billing","db":"users"},"WRAP: charge":{"amount":100}}
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	errs := jsonParityErrors()
+	errs["foreign-cause"] = errors.Just(io.EOF).Int("n", 6)
+	errs["phantom"] = errors.Spec(fmt.Errorf("foreign: %w", errors.New("deep").Int("k", 1)), new(1))
+	errs["from"] = errors.Wrap(errors.From(io.EOF).Int("n", 7), "wrap")
+	errs["error-value"] = errors.New("error value").Any("cause", io.ErrUnexpectedEOF)
+
+	for name, err := range errs {
+		data, merr := json.Marshal(err)
+		if merr != nil {
+			t.Errorf("%s: marshal error: %v", name, merr)
+			continue
+		}
+
+		decoded, derr := errors.Decode(data)
+		if derr != nil {
+			t.Errorf("%s: decode error: %v", name, derr)
+			continue
+		}
+
+		if got, want := decoded.Error(), err.Error(); got != want {
+			t.Errorf("%s: text %q, want %q", name, got, want)
+		}
+
+		// Flat context has no remote marks.
+		got := string(decoded.AppendJSON(nil, errors.ContextFlat))
+		want := string(err.AppendJSON(nil, errors.ContextFlat))
+		if got != want {
+			t.Errorf("%s: context\n%s\nwant\n%s", name, got, want)
+		}
+
+		again, _ := json.Marshal(decoded)
+		if string(again) != string(data) {
+			t.Errorf("%s: encoded again\n%s\nwant\n%s", name, again, data)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		`[]`,
+		`{"layers":[]}`,
+		`{"layers":[{"kind":"unknown"}]}`,
+		`{"layers":[{"kind":"cause"}]}`,
+		`{"layers":[{"kind":"new","attrs":[{"key":"k","type":"int64","value":"1"}]}]}`,
+		`{"layers":[{"kind":"new","attrs":[{"key":"k","type":"complex","value":1}]}]}`,
+	} {
+		if _, err := errors.Decode([]byte(data)); err == nil {
+			t.Errorf("no error decoding %s", data)
+		}
+	}
+}
+
+func TestMarshalJSONField(t *testing.T) {
+	type response struct {
+		Error *errors.Error `json:"error,omitempty"`
+	}
+
+	err := errors.Wrap(errors.New("connection refused").Int("port", 5432), "connect")
+	data, merr := json.Marshal(response{Error: err})
+	if merr != nil {
+		t.Fatal(merr)
+	}
+
+	var got response
+	if uerr := json.Unmarshal(data, &got); uerr != nil {
+		t.Fatal(uerr)
+	}
+	if got.Error == nil || got.Error.Error() != err.Error() {
+		t.Fatalf("decoded %v from %s", got.Error, data)
+	}
+
+	var empty response
+	if uerr := json.Unmarshal([]byte(`{"error":null}`), &empty); uerr != nil || empty.Error != nil {
+		t.Errorf("null decoded as %v: %v", empty.Error, uerr)
+	}
+
+	var nilErr *errors.Error
+	if data, merr := nilErr.MarshalJSON(); merr != nil || string(data) != "null" {
+		t.Errorf("nil error encoded as %s: %v", data, merr)
+	}
+}
@@ -1,16 +1,21 @@
 package errors
 
 import (
+	"iter"
 	"log/slog"
+	"reflect"
 )
 
 // Spec gives an error a given "spec" which is not shown in an output.
 // It is meant to be used for domain specific payloads without a need
 // for special kinds of errors.
+//
+// Specs given later, on outer layers, take precedence over the inner ones,
+// so a spec can be overridden by just giving a new one.
 func Spec(err error, mark any) *Error {
 	if e, ok := err.(*Error); ok {
 		e.attrs = append(e.attrs, errorAttr{
-			value: slog.AnyValue(mark),
+			value: markValue(mark),
 			kind:  errorAttrKindMarker,
 		})
 		return e
@@ -25,7 +30,7 @@ func Spec(err error, mark any) *Error {
 			kind:  errorAttrKindPhantomJust,
 		},
 		errorAttr{
-			value: slog.AnyValue(mark),
+			value: markValue(mark),
 			kind:  errorAttrKindMarker,
 		},
 	)
@@ -34,61 +39,119 @@ func Spec(err error, mark any) *Error {
 	}
 }
 
+// specMark keeps the spec as is, [slog.AnyValue] would turn specs of basic types
+// like int into values of other types.
+type specMark struct {
+	mark any
+}
+
+func markValue(mark any) slog.Value {
+	return slog.AnyValue(specMark{mark: mark})
+}
+
+// mark returns the spec of the marker attr.
+func (a errorAttr) mark() any {
+	return a.value.Any().(specMark).mark
+}
+
+// AsSpec returns the spec of the given type found in the error. Specs of sentinels given
+// with [WithSpec] are found as well, through any wrapping. The outermost spec wins,
+// see [AllSpecs] for the order.
 func AsSpec[T any](err error) (v T, ok bool) {
+	for v := range AllSpecs[T](err) {
+		return v, true
+	}
+
+	var zero T
+	return zero, false
+}
+
+// IsSpec checks if the error has a spec of the given type, see [AsSpec].
+func IsSpec[T any](err error) (ok bool) {
+	_, ok = AsSpec[T](err)
+	return ok
+}
+
+// AllSpecs iterates over specs of the given type found in the error, from the outermost
+// to the innermost ones. Specs of sentinels go last, the ones of children before the ones
+// of their parents.
+func AllSpecs[T any](err error) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		walkSpecs(err, yield)
+	}
+}
+
+// DropSpec hides specs of the given type from [AsSpec], [IsSpec] and [AllSpecs]. It is
+// meant for API boundaries, where internal specs must not leak out. Specs of this type
+// given after the drop, on outer layers, are still visible.
+func DropSpec[T any](err error) *Error {
+	return Spec(err, specDrop[T]{})
+}
+
+// specDrop is a spec hiding all inner specs of the type.
+type specDrop[T any] struct{}
+
+func (specDrop[T]) dropsSpec() string {
+	return "DropSpec[" + reflect.TypeFor[T]().String() + "]"
+}
+
+type specDropper interface {
+	dropsSpec() string
+}
+
+// walkSpecs passes specs found in the error to yield until it returns false
+// or a drop of specs is met, false is returned then.
+func walkSpecs[T any](err error, yield func(T) bool) bool {
 	e, ok := err.(*Error)
 	if !ok {
 		e, ok = AsType[*Error](err)
 		if !ok {
-			var zero T
-			return zero, false
+			return walkSentinelSpecs(err, yield)
 		}
 	}
 
 	var wrappedErr error
-	for _, attr := range e.attrs {
+	for i := len(e.attrs) - 1; i >= 0; i-- {
+		attr := e.attrs[i]
 		switch attr.kind {
 		case errorAttrKindMarker:
-			v, ok := attr.value.Any().(T)
-			if ok {
-				return v, true
+			mark := attr.mark()
+			if _, ok := mark.(specDrop[T]); ok {
+				return false
+			}
+			if _, ok := mark.(specDropper); ok {
+				continue
 			}
-		case errorAttrKindOutterWrap, errorAttrKindOutterJust:
+			if v, ok := mark.(T); ok && !yield(v) {
+				return false
+			}
+		case errorAttrKindOutterNew, errorAttrKindOutterWrap, errorAttrKindOutterJust, errorAttrKindPhantomJust:
 			wrappedErr = attr.value.Any().(error)
 		}
 	}
 
 	if wrappedErr == nil {
-		var zero T
-		return zero, false
+		return true
 	}
 
-	return AsSpec[T](wrappedErr)
+	return walkSpecs(wrappedErr, yield)
 }
 
-func IsSpec[T any](err error) (ok bool) {
-	e, ok := err.(*Error)
-	if !ok {
-		e, ok = AsType[*Error](err)
+// walkSentinelSpecs passes specs of the sentinel found in the error chain and of its parents.
+func walkSentinelSpecs[T any](err error, yield func(T) bool) bool {
+	for err != nil {
+		s, ok := AsType[*errorSentinel](err)
 		if !ok {
-			return false
+			break
 		}
-	}
 
-	var wrappedErr error
-	for _, attr := range e.attrs {
-		switch attr.kind {
-		case errorAttrKindMarker:
-			if _, ok := attr.value.Any().(T); ok {
-				return true
+		for _, spec := range s.specs {
+			if v, ok := spec.(T); ok && !yield(v) {
+				return false
 			}
-		case errorAttrKindOutterWrap, errorAttrKindOutterJust:
-			wrappedErr = attr.value.Any().(error)
 		}
+		err = s.parent
 	}
 
-	if wrappedErr == nil {
-		return false
-	}
-
-	return IsSpec[T](wrappedErr)
+	return true
 }
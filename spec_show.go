@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+var showSpecs bool
+
+// ShowSpecs enables rendering of specs given with [Spec] and [WithSpec], meant for
+// debugging of why [AsSpec] or [IsSpec] behave a certain way. Specs of a layer appear in it
+// as a single @spec list of "<Go type> = <value>" items, flat contexts have one list for
+// all layers. Values are formatted with their [slog.LogValuer] or [fmt.Stringer]
+// implementations when available.
+//
+// Like [InsertLocations], this is a development mode option.
+func ShowSpecs() {
+	showSpecs = true
+}
+
+// HideSpecs disables rendering of specs. This is the default mode.
+func HideSpecs() {
+	showSpecs = false
+}
+
+// specValue renders the spec.
+func specValue(mark any) slog.Value {
+	if d, ok := mark.(specDropper); ok {
+		return slog.StringValue(d.dropsSpec())
+	}
+
+	var text string
+	switch v := mark.(type) {
+	case slog.LogValuer:
+		text = v.LogValue().Resolve().String()
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		// Specs are often pointers to make them unique, addresses tell nothing.
+		if rv := reflect.ValueOf(mark); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			text = fmt.Sprintf("%+v", rv.Elem().Interface())
+		} else {
+			text = fmt.Sprintf("%+v", mark)
+		}
+	}
+
+	return slog.StringValue(fmt.Sprintf("%T = %s", mark, text))
+}
+
+// layerSentinelSpecs returns specs of the sentinel a layer is started over, so they
+// are shown in this layer.
+func layerSentinelSpecs(attr errorAttr) []any {
+	switch attr.kind {
+	case errorAttrKindOutterNew, errorAttrKindOutterWrap, errorAttrKindOutterJust:
+	default:
+		return nil
+	}
+
+	err, ok := attr.value.Any().(error)
+	if !ok {
+		return nil
+	}
+
+	var res []any
+	for err != nil {
+		s, ok := AsType[*errorSentinel](err)
+		if !ok {
+			break
+		}
+		res = append(res, s.specs...)
+		err = s.parent
+	}
+
+	return res
+}
@@ -3,8 +3,10 @@ package errors_test
 import (
 	"fmt"
 	"io"
+	"testing"
 
 	"github.com/sirkon/errors"
+	"github.com/sirkon/errors/errorsctx"
 )
 
 func ExampleSpec() {
@@ -37,3 +39,162 @@ func asSpec(err error) int {
 
 	return *res
 }
+
+type httpStatus int
+
+func ExampleWithSpec() {
+	var (
+		errNotFound     = errors.NewSentinel("not found", errors.WithSpec(httpStatus(404)))
+		errUserNotFound = errors.NewSentinelUnder(errNotFound, "user not found")
+		errConflict     = errors.NewSentinel("conflict")
+	)
+
+	for _, err := range []error{
+		errNotFound,
+		errors.Wrap(errNotFound, "get order").Str("order", "42"),
+		errors.From(errUserNotFound).Str("user", "joe"),
+		errors.Wrap(fmt.Errorf("lookup: %w", errUserNotFound), "get user"),
+		errors.Spec(errors.Wrap(errNotFound, "get order"), httpStatus(410)),
+		errors.Wrap(errConflict, "update user"),
+	} {
+		status, ok := errors.AsSpec[httpStatus](err)
+		fmt.Println(status, ok)
+	}
+
+	// Output:
+	// 404 true
+	// 404 true
+	// 404 true
+	// 404 true
+	// 410 true
+	// 0 false
+}
+
+type retryPolicy string
+
+func ExampleAllSpecs() {
+	errUnavailable := errors.NewSentinel("unavailable", errors.WithSpec(retryPolicy("backoff")))
+
+	var err error
+	err = errors.Wrap(errUnavailable, "call storage")
+	err = errors.Spec(err, retryPolicy("fast"))
+	err = fmt.Errorf("foreign: %w", err)
+	err = errors.Spec(errors.Wrap(err, "handle"), retryPolicy("never"))
+
+	for policy := range errors.AllSpecs[retryPolicy](err) {
+		fmt.Println(policy)
+	}
+	fmt.Println(errors.AsSpec[retryPolicy](err))
+
+	// Output:
+	// never
+	// fast
+	// backoff
+	// never true
+}
+
+func ExampleDropSpec() {
+	errUnavailable := errors.NewSentinel("unavailable", errors.WithSpec(retryPolicy("backoff")))
+
+	err := errors.Spec(errors.Wrap(errUnavailable, "call storage"), httpStatus(503))
+	err = errors.DropSpec[retryPolicy](err)
+	fmt.Println(err, errors.IsSpec[retryPolicy](err), errors.IsSpec[httpStatus](err))
+
+	err = errors.Spec(errors.Wrap(err, "public api"), retryPolicy("client"))
+	fmt.Println(errors.AsSpec[retryPolicy](err))
+	fmt.Println(errors.Is(err, errUnavailable))
+
+	// Output:
+	// call storage: unavailable false true
+	// client true
+	// true
+}
+
+func ExampleShowSpecs() {
+	errors.ShowSpecs()
+	defer errors.HideSpecs()
+
+	errNotFound := errors.NewSentinel("not found", errors.WithSpec(httpStatus(404)))
+	err := errors.Wrap(errNotFound, "get user").Str("user", "joe")
+	err = errors.Spec(errors.Wrap(err, "handle"), retryPolicy("never"))
+	err = errors.DropSpec[httpStatus](err)
+	fmt.Println(string(err.AppendJSON(nil, errors.ContextTree)))
+
+	err = errors.Spec(errors.New("marked"), new(42))
+	fmt.Println(string(err.AppendJSON(nil, errors.ContextFlat)))
+
+	// Output:
+	// {"WRAP: get user":{"user":"joe","@spec":["errors_test.httpStatus = 404"]},"WRAP: handle":{"@spec":["errors_test.retryPolicy = never","DropSpec[errors_test.httpStatus]"]}}
+	// {"@spec":["*int = 42"]}
+}
+
+func TestShowSpecsLayers(t *testing.T) {
+	errors.ShowSpecs()
+	defer errors.HideSpecs()
+
+	errNotFound := errors.NewSentinel("not found", errors.WithSpec(httpStatus(404)))
+	err := errors.Spec(errors.Wrap(errNotFound, "get user"), retryPolicy("never"))
+	err = errors.Spec(errors.Spec(errors.Wrap(err, "handle"), httpStatus(500)), retryPolicy("fast"))
+
+	if got, want := string(err.AppendJSON(nil, errors.ContextFlat)), `{"@spec":["errors_test.httpStatus = 404","errors_test.retryPolicy = never","errors_test.httpStatus = 500","errors_test.retryPolicy = fast"]}`; got != want {
+		t.Errorf("flat JSON %s, want %s", got, want)
+	}
+
+	var c errorsctx.Consumer
+	errors.GetContextDeliverer(err).Deliver(&c)
+	var got []string
+	for _, layer := range c.Layers {
+		for _, pair := range layer.Pairs {
+			got = append(got, fmt.Sprintf("%s %s=%v", layer, pair.Key, pair.Value.Any()))
+		}
+	}
+	want := []string{
+		"WRAP: get user @spec=[errors_test.httpStatus = 404 errors_test.retryPolicy = never]",
+		"WRAP: handle @spec=[errors_test.httpStatus = 500 errors_test.retryPolicy = fast]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+}
+
+func TestSpecIs(t *testing.T) {
+	errUserNotFound := errors.NewSentinelUnder(io.EOF, "user not found")
+
+	for name, err := range map[string]error{
+		"foreign":  errors.Spec(fmt.Errorf("read: %w", io.EOF), new(1)),
+		"sentinel": errors.Spec(errUserNotFound, new(1)),
+		"wrapped":  errors.Wrap(errors.Spec(errUserNotFound, new(1)), "get user").Int("id", 1),
+	} {
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("%s: does not match the wrapped error", name)
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: matches an unrelated error", name)
+		}
+	}
+}
+
+func TestShowSpecsForeign(t *testing.T) {
+	errors.ShowSpecs()
+	defer errors.HideSpecs()
+
+	err := errors.Spec(fmt.Errorf("foreign: %w", io.EOF), 42).Str("k", "v")
+	if v, ok := errors.AsSpec[int](err); !ok || v != 42 {
+		t.Errorf("spec %d %v", v, ok)
+	}
+	if got, want := string(err.AppendJSON(nil, errors.ContextFlat)), `{"k":"v","@spec":["int = 42"]}`; got != want {
+		t.Errorf("flat JSON %s, want %s", got, want)
+	}
+
+	var c errorsctx.Consumer
+	errors.GetContextDeliverer(err).Deliver(&c)
+	var got []string
+	for _, layer := range c.Layers {
+		for _, pair := range layer.Pairs {
+			got = append(got, fmt.Sprintf("%s %s=%v", layer, pair.Key, pair.Value.Any()))
+		}
+	}
+	if want := []string{"CTX k=v", "CTX @spec=[int = 42]"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+}